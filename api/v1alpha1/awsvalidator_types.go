@@ -26,6 +26,9 @@ import (
 type AwsValidatorSpec struct {
 	Auth          AwsAuth `json:"auth,omitempty" yaml:"auth,omitempty"`
 	DefaultRegion string  `json:"defaultRegion" yaml:"defaultRegion"`
+	// Accounts to run every rule against by assuming a role in each (optional).
+	// If unset, rules run against the account that the configured credentials belong to.
+	Accounts *AccountTargets `json:"accounts,omitempty" yaml:"accounts,omitempty"`
 	// +kubebuilder:validation:MaxItems=5
 	// +kubebuilder:validation:XValidation:message="IamRoleRules must have unique IamRoleNames",rule="self.all(e, size(self.filter(x, x.iamRoleName == e.iamRoleName)) == 1)"
 	IamRoleRules []IamRoleRule `json:"iamRoleRules,omitempty" yaml:"iamRoleRules,omitempty"`
@@ -39,6 +42,24 @@ type AwsValidatorSpec struct {
 	// +kubebuilder:validation:XValidation:message="IamPolicyRules must have unique ARNs",rule="self.all(e, size(self.filter(x, x.iamPolicyArn == e.iamPolicyArn)) == 1)"
 	IamPolicyRules []IamPolicyRule `json:"iamPolicyRules,omitempty" yaml:"iamPolicyRules,omitempty"`
 	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="IamIrsaRules must have unique IamRoleNames",rule="self.all(e, size(self.filter(x, x.iamRoleName == e.iamRoleName)) == 1)"
+	IamIrsaRules []IamIrsaRule `json:"iamIrsaRules,omitempty" yaml:"iamIrsaRules,omitempty"`
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="IamCustomPolicyRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	IamCustomPolicyRules []IamCustomPolicyRule `json:"iamCustomPolicyRules,omitempty" yaml:"iamCustomPolicyRules,omitempty"`
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="IamUserCredentialRules must have unique IamUserNames",rule="self.all(e, size(self.filter(x, x.iamUserName == e.iamUserName)) == 1)"
+	IamUserCredentialRules []IamUserCredentialRule `json:"iamUserCredentialRules,omitempty" yaml:"iamUserCredentialRules,omitempty"`
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="IamExistenceRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	IamExistenceRules []IamExistenceRule `json:"iamExistenceRules,omitempty" yaml:"iamExistenceRules,omitempty"`
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="IamQuotaRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	IamQuotaRules []IamQuotaRule `json:"iamQuotaRules,omitempty" yaml:"iamQuotaRules,omitempty"`
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:XValidation:message="AccountRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
+	AccountRules []AccountRule `json:"accountRules,omitempty" yaml:"accountRules,omitempty"`
+	// +kubebuilder:validation:MaxItems=5
 	// +kubebuilder:validation:XValidation:message="ServiceQuotaRules must have unique names",rule="self.all(e, size(self.filter(x, x.name == e.name)) == 1)"
 	ServiceQuotaRules []ServiceQuotaRule `json:"serviceQuotaRules,omitempty" yaml:"serviceQuotaRules,omitempty"`
 	// +kubebuilder:validation:MaxItems=5
@@ -46,9 +67,47 @@ type AwsValidatorSpec struct {
 	TagRules []TagRule `json:"tagRules,omitempty" yaml:"tagRules,omitempty"`
 }
 
+// ResultCount returns the number of ValidationRuleResults expected for the spec.
+// Rules run once per target account listed in the spec. Accounts enumerated from an organizational unit
+// are unknown until they're resolved, in which case RuleResultCount must be scaled by the number of resolved accounts.
 func (s AwsValidatorSpec) ResultCount() int {
-	return len(s.IamGroupRules) + len(s.IamPolicyRules) + len(s.IamRoleRules) + len(s.IamUserRules) +
-		len(s.ServiceQuotaRules) + len(s.TagRules)
+	if s.Accounts != nil && len(s.Accounts.AccountIDs) > 0 {
+		return s.RuleResultCount() * len(s.Accounts.AccountIDs)
+	}
+	return s.RuleResultCount()
+}
+
+// RuleResultCount returns the number of ValidationRuleResults expected for the spec in a single account.
+// If the spec has any rules, the plugin credentials preflight contributes an additional result.
+func (s AwsValidatorSpec) RuleResultCount() int {
+	count := len(s.IamGroupRules) + len(s.IamPolicyRules) + len(s.IamRoleRules) + len(s.IamUserRules) +
+		len(s.IamIrsaRules) + len(s.IamCustomPolicyRules) + len(s.IamUserCredentialRules) + len(s.IamExistenceRules) + len(s.IamQuotaRules) + len(s.AccountRules) + len(s.ServiceQuotaRules) + len(s.TagRules)
+	if count > 0 {
+		count++
+	}
+	return count
+}
+
+// AccountTargets defines the accounts that an AwsValidator's rules run against
+// +kubebuilder:validation:XValidation:message="At least one of accountIds or organizationalUnitId must be set",rule="has(self.accountIds) || has(self.organizationalUnitId)"
+type AccountTargets struct {
+	// IDs of the accounts to validate (optional).
+	// +kubebuilder:validation:MaxItems=100
+	// +kubebuilder:validation:items:Pattern=`^[0-9]{12}$`
+	AccountIDs []string `json:"accountIds,omitempty" yaml:"accountIds,omitempty"`
+	// ID of an AWS Organizations organizational unit (or root) whose active member accounts are validated (optional).
+	// The accounts are enumerated via organizations:ListAccountsForParent using the configured credentials.
+	// Only accounts directly in the organizational unit are included, not those in child organizational units.
+	// +kubebuilder:validation:Pattern=`^(r-[0-9a-z]{4,32}|ou-[0-9a-z]{4,32}-[a-z0-9]{8,32})$`
+	OrganizationalUnitID string `json:"organizationalUnitId,omitempty" yaml:"organizationalUnitId,omitempty"`
+	// Name of the IAM role to assume in each account, e.g., OrganizationAccountAccessRole.
+	// The role is assumed after any roles configured in auth.stsAuth.
+	RoleName string `json:"roleName" yaml:"roleName"`
+	// An identifier for the assumed role sessions.
+	// +kubebuilder:default=validator-plugin-aws
+	RoleSessionName string `json:"roleSessionName,omitempty" yaml:"roleSessionName,omitempty"`
+	// A unique identifier that might be required to assume the role in each account (optional).
+	ExternalId string `json:"externalId,omitempty" yaml:"externalId,omitempty"`
 }
 
 type AwsAuth struct {
@@ -59,8 +118,89 @@ type AwsAuth struct {
 	// The secret data's keys and values are expected to align with valid AWS environment variable credentials,
 	// per the options defined in https://aws.github.io/aws-sdk-go-v2/docs/configuring-sdk/#environment-variables.
 	SecretName string `json:"secretName,omitempty" yaml:"secretName,omitempty"`
+	// A Secret containing AWS shared config and/or credentials files, and the profile to use from them (optional).
+	// If set, secretName is not required.
+	SharedConfig *AwsSharedConfig `json:"sharedConfig,omitempty" yaml:"sharedConfig,omitempty"`
+	// Web identity authentication properties, e.g., for IAM roles for Service Accounts (optional).
+	// If set, the role is assumed with the projected service account token instead of using the default credential chain.
+	// If set, secretName is not required.
+	WebIdentity *AwsWebIdentityAuth `json:"webIdentity,omitempty" yaml:"webIdentity,omitempty"`
 	// STS authentication properties (optional)
 	StsAuth *AwsSTSAuth `json:"stsAuth,omitempty" yaml:"stsAuth,omitempty"`
+	// The ID of the AWS account that the credentials must belong to (optional).
+	// If the caller identity belongs to a different account, every rule fails.
+	// +kubebuilder:validation:Pattern=`^\d{12}$`
+	ExpectedAccountID string `json:"expectedAccountId,omitempty" yaml:"expectedAccountId,omitempty"`
+	// The AWS partition that the credentials must belong to (optional).
+	// If the caller identity belongs to a different partition, every rule fails.
+	// +kubebuilder:validation:Enum=aws;aws-cn;aws-us-gov
+	ExpectedPartition string `json:"expectedPartition,omitempty" yaml:"expectedPartition,omitempty"`
+	// Endpoint, proxy, and CA bundle configuration for the AWS service clients (optional).
+	ClientConfig *AwsClientConfig `json:"clientConfig,omitempty" yaml:"clientConfig,omitempty"`
+}
+
+// AwsWebIdentityAuth assumes an IAM role via sts:AssumeRoleWithWebIdentity using a projected service account token
+type AwsWebIdentityAuth struct {
+	// The Amazon Resource Name (ARN) of the role to assume.
+	RoleArn string `json:"roleArn" yaml:"roleArn"`
+	// Path to the projected service account token in the plugin's container.
+	// +kubebuilder:default=/var/run/secrets/validator-plugin-aws/serviceaccount/token
+	TokenFile string `json:"tokenFile,omitempty" yaml:"tokenFile,omitempty"`
+	// An identifier for the assumed role session.
+	// +kubebuilder:default=validator-plugin-aws
+	RoleSessionName string `json:"roleSessionName,omitempty" yaml:"roleSessionName,omitempty"`
+}
+
+// AwsSharedConfig references AWS shared config and credentials files in a Secret.
+// Profiles are resolved by the AWS SDK's shared config loader, so they may use role_arn, source_profile,
+// credential_process, web_identity_token_file, etc. Files referenced by a profile must exist in the plugin's container.
+type AwsSharedConfig struct {
+	// Name of a Secret in the same namespace as the AwsValidator that contains the files.
+	SecretName string `json:"secretName" yaml:"secretName"`
+	// The key of the shared config file in the Secret.
+	// +kubebuilder:default=config
+	ConfigKey string `json:"configKey,omitempty" yaml:"configKey,omitempty"`
+	// The key of the shared credentials file in the Secret.
+	// +kubebuilder:default=credentials
+	CredentialsKey string `json:"credentialsKey,omitempty" yaml:"credentialsKey,omitempty"`
+	// The name of the profile to use.
+	// +kubebuilder:default=default
+	Profile string `json:"profile,omitempty" yaml:"profile,omitempty"`
+}
+
+// AwsClientConfig configures how the AWS service clients reach AWS.
+// Options that are not set fall back to the plugin's AWS SDK environment variables, e.g., AWS_ENDPOINT_URL,
+// AWS_USE_FIPS_ENDPOINT, AWS_USE_DUALSTACK_ENDPOINT, AWS_CA_BUNDLE, HTTPS_PROXY, and NO_PROXY.
+type AwsClientConfig struct {
+	// Endpoint URL used for every AWS service, e.g., a local AWS emulator (optional).
+	EndpointURL string `json:"endpointUrl,omitempty" yaml:"endpointUrl,omitempty"`
+	// Endpoint URL overrides by service, e.g., VPC interface endpoints (optional).
+	// Supported services are cloudwatch, ec2, efs, elb, elbv2, iam, kms, organizations, s3, servicequotas, sqs, and sts.
+	// +kubebuilder:validation:MaxProperties=12
+	// +kubebuilder:validation:XValidation:message="ServiceEndpoints keys must be one of cloudwatch, ec2, efs, elb, elbv2, iam, kms, organizations, s3, servicequotas, sqs, sts",rule="self.all(k, k in ['cloudwatch', 'ec2', 'efs', 'elb', 'elbv2', 'iam', 'kms', 'organizations', 's3', 'servicequotas', 'sqs', 'sts'])"
+	ServiceEndpoints map[string]string `json:"serviceEndpoints,omitempty" yaml:"serviceEndpoints,omitempty"`
+	// If true, FIPS endpoints are used.
+	UseFIPSEndpoint bool `json:"useFipsEndpoint,omitempty" yaml:"useFipsEndpoint,omitempty"`
+	// If true, dual-stack (IPv4 & IPv6) endpoints are used.
+	UseDualStackEndpoint bool `json:"useDualStackEndpoint,omitempty" yaml:"useDualStackEndpoint,omitempty"`
+	// URL of the HTTP(S) proxy used for AWS API requests (optional).
+	ProxyURL string `json:"proxyUrl,omitempty" yaml:"proxyUrl,omitempty"`
+	// Comma-separated hosts, domains, IPs, and CIDRs that bypass the proxy, in NO_PROXY format (optional).
+	NoProxy string `json:"noProxy,omitempty" yaml:"noProxy,omitempty"`
+	// A PEM-encoded CA bundle used to verify AWS endpoints and the proxy (optional).
+	CABundle *CABundleSource `json:"caBundle,omitempty" yaml:"caBundle,omitempty"`
+}
+
+// CABundleSource references a PEM-encoded CA bundle in a Secret or ConfigMap in the same namespace as the AwsValidator.
+// +kubebuilder:validation:XValidation:message="Exactly one of secretName or configMapName must be set",rule="has(self.secretName) != has(self.configMapName)"
+type CABundleSource struct {
+	// Name of a Secret containing the CA bundle.
+	SecretName string `json:"secretName,omitempty" yaml:"secretName,omitempty"`
+	// Name of a ConfigMap containing the CA bundle.
+	ConfigMapName string `json:"configMapName,omitempty" yaml:"configMapName,omitempty"`
+	// The key of the CA bundle in the Secret or ConfigMap.
+	// +kubebuilder:default=ca.crt
+	Key string `json:"key,omitempty" yaml:"key,omitempty"`
 }
 
 type AwsSTSAuth struct {
@@ -75,11 +215,111 @@ type AwsSTSAuth struct {
 	DurationSeconds int `json:"durationSeconds" yaml:"durationSeconds"`
 	// A unique identifier that might be required when you assume a role in another account.
 	ExternalId string `json:"externalId,omitempty" yaml:"externalId,omitempty"`
+	// Session tags to pass when assuming the role (optional).
+	SessionTags []SessionTag `json:"sessionTags,omitempty" yaml:"sessionTags,omitempty"`
+	// The source identity to set when assuming the role (optional).
+	SourceIdentity string `json:"sourceIdentity,omitempty" yaml:"sourceIdentity,omitempty"`
+	// ARNs of IAM managed policies to use as session policies when assuming the role (optional).
+	PolicyArns []string `json:"policyArns,omitempty" yaml:"policyArns,omitempty"`
+	// Additional roles to assume in order after the role above, each using the credentials of the previous role (optional).
+	// Note that AWS limits the duration of chained role sessions to one hour.
+	// +kubebuilder:validation:MaxItems=5
+	RoleChain []AwsSTSRole `json:"roleChain,omitempty" yaml:"roleChain,omitempty"`
+}
+
+// Hops returns each role assumption in an STS role chain, in order
+func (a AwsSTSAuth) Hops() []AwsSTSRole {
+	hops := []AwsSTSRole{{
+		RoleArn:         a.RoleArn,
+		RoleSessionName: a.RoleSessionName,
+		DurationSeconds: a.DurationSeconds,
+		ExternalId:      a.ExternalId,
+		SessionTags:     a.SessionTags,
+		SourceIdentity:  a.SourceIdentity,
+		PolicyArns:      a.PolicyArns,
+	}}
+	return append(hops, a.RoleChain...)
+}
+
+// AwsSTSRole is a role assumption in an STS role chain
+type AwsSTSRole struct {
+	// The Amazon Resource Name (ARN) of the role to assume.
+	RoleArn string `json:"roleArn" yaml:"roleArn"`
+	// An identifier for the assumed role session.
+	RoleSessionName string `json:"roleSessionName" yaml:"roleSessionName"`
+	// The duration, in seconds, of the role session.
+	// +kubebuilder:default=3600
+	// +kubebuilder:validation:Minimum=900
+	// +kubebuilder:validation:Maximum=43200
+	DurationSeconds int `json:"durationSeconds" yaml:"durationSeconds"`
+	// A unique identifier that might be required when you assume a role in another account.
+	ExternalId string `json:"externalId,omitempty" yaml:"externalId,omitempty"`
+	// Session tags to pass when assuming the role (optional).
+	SessionTags []SessionTag `json:"sessionTags,omitempty" yaml:"sessionTags,omitempty"`
+	// The source identity to set when assuming the role (optional).
+	SourceIdentity string `json:"sourceIdentity,omitempty" yaml:"sourceIdentity,omitempty"`
+	// ARNs of IAM managed policies to use as session policies when assuming the role (optional).
+	PolicyArns []string `json:"policyArns,omitempty" yaml:"policyArns,omitempty"`
+}
+
+// SessionTag is a tag passed when assuming an IAM role
+type SessionTag struct {
+	Key   string `json:"key" yaml:"key"`
+	Value string `json:"value" yaml:"value"`
+	// If true, the tag persists to subsequent sessions in a role chain.
+	Transitive bool `json:"transitive,omitempty" yaml:"transitive,omitempty"`
+}
+
+// RuleOverrides overrides the auth and region used to evaluate a rule.
+// Rules with the same overrides share AWS service clients.
+type RuleOverrides struct {
+	// Overrides the credentials used to evaluate the rule (optional).
+	// All other auth settings, e.g., clientConfig and expectedPartition, are inherited from spec.auth.
+	Auth *RuleAuth `json:"auth,omitempty" yaml:"auth,omitempty"`
+	// The AWS region to evaluate the rule in (optional).
+	// Takes precedence over the rule's region, if any, and spec.defaultRegion.
+	Region string `json:"region,omitempty" yaml:"region,omitempty"`
+}
+
+// AuthOverride returns the rule's auth override, if any
+func (o *RuleOverrides) AuthOverride() *RuleAuth {
+	if o == nil {
+		return nil
+	}
+	return o.Auth
+}
+
+// RegionOr returns the rule's region override, or a fallback region if the region is not overridden
+func (o *RuleOverrides) RegionOr(fallback string) string {
+	if o == nil || o.Region == "" {
+		return fallback
+	}
+	return o.Region
+}
+
+// RuleAuth overrides the credentials used to evaluate a rule
+// +kubebuilder:validation:XValidation:message="At least one of secretName or stsAuth must be set",rule="has(self.secretName) || has(self.stsAuth)"
+type RuleAuth struct {
+	// The name of a Secret containing the AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, and (optionally) AWS_SESSION_TOKEN
+	// keys to use as the rule's base credentials (optional). Defaults to the base credentials of spec.auth.
+	SecretName string `json:"secretName,omitempty" yaml:"secretName,omitempty"`
+	// STS authentication properties (optional).
+	// Replaces spec.auth.stsAuth, i.e., the role(s) are assumed using the rule's base credentials.
+	StsAuth *AwsSTSAuth `json:"stsAuth,omitempty" yaml:"stsAuth,omitempty"`
 }
 
 type IamRoleRule struct {
 	IamRoleName string           `json:"iamRoleName" yaml:"iamRoleName"`
 	Policies    []PolicyDocument `json:"iamPolicies" yaml:"iamPolicies"`
+	// Report granted actions outside of the rule's IAM policies as warnings (Warn) or failures (Fail). Disabled if unset.
+	ExcessPermissions ExcessPermissionsMode `json:"excessPermissions,omitempty" yaml:"excessPermissions,omitempty"`
+	// Report services that the role has not used, per the role's IAM service last accessed data (optional).
+	LastAccessed *LastAccessedCheck `json:"lastAccessed,omitempty" yaml:"lastAccessed,omitempty"`
+	// If true, the bucket, key, and queue policies of S3, KMS, and SQS resources in the rule's policies are evaluated for the role.
+	// Required actions that a resource policy denies to the role, or that a KMS key policy doesn't grant it, are reported as failures.
+	ResourcePolicies bool `json:"resourcePolicies,omitempty" yaml:"resourcePolicies,omitempty"`
+	// Overrides the auth and region used to evaluate the rule (optional)
+	Overrides *RuleOverrides `json:"overrides,omitempty" yaml:"overrides,omitempty"`
 }
 
 func (r IamRoleRule) Name() string {
@@ -90,9 +330,28 @@ func (r IamRoleRule) IAMPolicies() []PolicyDocument {
 	return r.Policies
 }
 
+func (r IamRoleRule) ExcessPermissionsMode() ExcessPermissionsMode {
+	return r.ExcessPermissions
+}
+
+func (r IamRoleRule) ResourcePoliciesEnabled() bool {
+	return r.ResourcePolicies
+}
+
+func (r IamRoleRule) LastAccessedCheck() *LastAccessedCheck {
+	return r.LastAccessed
+}
+
 type IamUserRule struct {
 	IamUserName string           `json:"iamUserName" yaml:"iamUserName"`
 	Policies    []PolicyDocument `json:"iamPolicies" yaml:"iamPolicies"`
+	// Report granted actions outside of the rule's IAM policies as warnings (Warn) or failures (Fail). Disabled if unset.
+	ExcessPermissions ExcessPermissionsMode `json:"excessPermissions,omitempty" yaml:"excessPermissions,omitempty"`
+	// If true, the user's access to S3 buckets, KMS keys, and SQS queues in the rule's policies is also checked against
+	// their resource-based policies, e.g., an S3 bucket policy that denies the user s3:PutObject is reported as a failure.
+	ResourcePolicies bool `json:"resourcePolicies,omitempty" yaml:"resourcePolicies,omitempty"`
+	// Overrides the auth and region used to evaluate the rule (optional)
+	Overrides *RuleOverrides `json:"overrides,omitempty" yaml:"overrides,omitempty"`
 }
 
 func (r IamUserRule) Name() string {
@@ -103,9 +362,21 @@ func (r IamUserRule) IAMPolicies() []PolicyDocument {
 	return r.Policies
 }
 
+func (r IamUserRule) ExcessPermissionsMode() ExcessPermissionsMode {
+	return r.ExcessPermissions
+}
+
+func (r IamUserRule) ResourcePoliciesEnabled() bool {
+	return r.ResourcePolicies
+}
+
 type IamGroupRule struct {
 	IamGroupName string           `json:"iamGroupName" yaml:"iamGroupName"`
 	Policies     []PolicyDocument `json:"iamPolicies" yaml:"iamPolicies"`
+	// Report granted actions outside of the rule's IAM policies as warnings (Warn) or failures (Fail). Disabled if unset.
+	ExcessPermissions ExcessPermissionsMode `json:"excessPermissions,omitempty" yaml:"excessPermissions,omitempty"`
+	// Overrides the auth and region used to evaluate the rule (optional)
+	Overrides *RuleOverrides `json:"overrides,omitempty" yaml:"overrides,omitempty"`
 }
 
 func (r IamGroupRule) Name() string {
@@ -116,19 +387,157 @@ func (r IamGroupRule) IAMPolicies() []PolicyDocument {
 	return r.Policies
 }
 
+func (r IamGroupRule) ExcessPermissionsMode() ExcessPermissionsMode {
+	return r.ExcessPermissions
+}
+
 type IamPolicyRule struct {
 	IamPolicyARN string           `json:"iamPolicyArn" yaml:"iamPolicyArn"`
 	Policies     []PolicyDocument `json:"iamPolicies" yaml:"iamPolicies"`
+	// The ID of a specific IAM policy version to validate, e.g., v3, or LatestNonDefault to validate the most recently
+	// created non-default version. The default version is validated if unset.
+	PolicyVersion string `json:"policyVersion,omitempty" yaml:"policyVersion,omitempty"`
+	// Report granted actions outside of the rule's IAM policies as warnings (Warn) or failures (Fail). Disabled if unset.
+	ExcessPermissions ExcessPermissionsMode `json:"excessPermissions,omitempty" yaml:"excessPermissions,omitempty"`
+	// Overrides the auth and region used to evaluate the rule (optional)
+	Overrides *RuleOverrides `json:"overrides,omitempty" yaml:"overrides,omitempty"`
 }
 
 func (r IamPolicyRule) Name() string {
 	return r.IamPolicyARN
 }
 
+func (r IamPolicyRule) TargetPolicyVersion() string {
+	return r.PolicyVersion
+}
+
 func (r IamPolicyRule) IAMPolicies() []PolicyDocument {
 	return r.Policies
 }
 
+func (r IamPolicyRule) ExcessPermissionsMode() ExcessPermissionsMode {
+	return r.ExcessPermissions
+}
+
+// IamIrsaRule validates that an IAM role can be assumed by a Kubernetes service account
+// via IAM roles for service accounts (IRSA) and that it grants the declared permissions.
+type IamIrsaRule struct {
+	IamRoleName string `json:"iamRoleName" yaml:"iamRoleName"`
+	// The OIDC issuer URL of the EKS cluster, e.g., https://oidc.eks.us-west-2.amazonaws.com/id/EXAMPLED539D4633E53DE1B71EXAMPLE
+	OidcIssuerURL string `json:"oidcIssuerUrl" yaml:"oidcIssuerUrl"`
+	// The namespace of the Kubernetes service account that assumes the IAM role.
+	ServiceAccountNamespace string `json:"serviceAccountNamespace" yaml:"serviceAccountNamespace"`
+	// The name of the Kubernetes service account that assumes the IAM role.
+	ServiceAccountName string           `json:"serviceAccountName" yaml:"serviceAccountName"`
+	Policies           []PolicyDocument `json:"iamPolicies" yaml:"iamPolicies"`
+	// Report granted actions outside of the rule's IAM policies as warnings (Warn) or failures (Fail). Disabled if unset.
+	ExcessPermissions ExcessPermissionsMode `json:"excessPermissions,omitempty" yaml:"excessPermissions,omitempty"`
+	// Report services that the role has not used, per the role's IAM service last accessed data (optional).
+	LastAccessed *LastAccessedCheck `json:"lastAccessed,omitempty" yaml:"lastAccessed,omitempty"`
+	// If true, the IRSA role's required actions on S3, KMS, and SQS resources must also be allowed by the resources'
+	// bucket, key, or queue policies, so that pods using the service account can access them.
+	ResourcePolicies bool `json:"resourcePolicies,omitempty" yaml:"resourcePolicies,omitempty"`
+	// Overrides the auth and region used to evaluate the rule (optional)
+	Overrides *RuleOverrides `json:"overrides,omitempty" yaml:"overrides,omitempty"`
+}
+
+func (r IamIrsaRule) Name() string {
+	return r.IamRoleName
+}
+
+func (r IamIrsaRule) IAMPolicies() []PolicyDocument {
+	return r.Policies
+}
+
+func (r IamIrsaRule) ExcessPermissionsMode() ExcessPermissionsMode {
+	return r.ExcessPermissions
+}
+
+func (r IamIrsaRule) ResourcePoliciesEnabled() bool {
+	return r.ResourcePolicies
+}
+
+func (r IamIrsaRule) LastAccessedCheck() *LastAccessedCheck {
+	return r.LastAccessed
+}
+
+// IamCustomPolicyRule validates a proposed set of IAM policy documents prior to creating an IAM principal
+type IamCustomPolicyRule struct {
+	RuleName string `json:"name" yaml:"name"`
+	// The proposed IAM policy documents, in JSON format.
+	// +kubebuilder:validation:MinItems=1
+	PolicyDocuments []string `json:"policyDocuments" yaml:"policyDocuments"`
+	// A proposed permissions boundary policy document, in JSON format (optional)
+	PermissionsBoundary string `json:"permissionsBoundary,omitempty" yaml:"permissionsBoundary,omitempty"`
+	// If true, the proposed policy documents are additionally evaluated via the IAM policy simulator to
+	// determine whether the permissions boundary would deny any required action.
+	Simulate bool `json:"simulate,omitempty" yaml:"simulate,omitempty"`
+	// The ARN of an existing IAM user, group, or role in the target account that the proposed policy documents are
+	// also simulated for if simulate is true, to determine whether an SCP that applies to the principal's account
+	// would deny any required action (optional). Only denials by an SCP are reported for the principal.
+	SimulationPrincipalArn string           `json:"simulationPrincipalArn,omitempty" yaml:"simulationPrincipalArn,omitempty"`
+	Policies               []PolicyDocument `json:"iamPolicies" yaml:"iamPolicies"`
+	// Report granted actions outside of the rule's IAM policies as warnings (Warn) or failures (Fail). Disabled if unset.
+	ExcessPermissions ExcessPermissionsMode `json:"excessPermissions,omitempty" yaml:"excessPermissions,omitempty"`
+	// Overrides the auth and region used to evaluate the rule (optional)
+	Overrides *RuleOverrides `json:"overrides,omitempty" yaml:"overrides,omitempty"`
+}
+
+func (r IamCustomPolicyRule) Name() string {
+	return r.RuleName
+}
+
+func (r IamCustomPolicyRule) IAMPolicies() []PolicyDocument {
+	return r.Policies
+}
+
+func (r IamCustomPolicyRule) ExcessPermissionsMode() ExcessPermissionsMode {
+	return r.ExcessPermissions
+}
+
+// IamUserCredentialRule validates that an IAM user's credentials comply with a rotation policy
+type IamUserCredentialRule struct {
+	IamUserName string `json:"iamUserName" yaml:"iamUserName"`
+	// The maximum age, in days, of each active access key. Disabled if unset.
+	MaxAccessKeyAgeDays int `json:"maxAccessKeyAgeDays,omitempty" yaml:"maxAccessKeyAgeDays,omitempty"`
+	// The maximum number of days since each active access key was last used. Disabled if unset.
+	MaxAccessKeyUnusedDays int `json:"maxAccessKeyUnusedDays,omitempty" yaml:"maxAccessKeyUnusedDays,omitempty"`
+	// The maximum age, in days, of the user's console password. Disabled if unset.
+	MaxPasswordAgeDays int `json:"maxPasswordAgeDays,omitempty" yaml:"maxPasswordAgeDays,omitempty"`
+	// If true, the user must have at least one MFA device.
+	RequireMFA bool `json:"requireMfa,omitempty" yaml:"requireMfa,omitempty"`
+	// If true, the user may have more than one active access key.
+	AllowMultipleActiveAccessKeys bool `json:"allowMultipleActiveAccessKeys,omitempty" yaml:"allowMultipleActiveAccessKeys,omitempty"`
+	// Overrides the auth and region used to evaluate the rule (optional)
+	Overrides *RuleOverrides `json:"overrides,omitempty" yaml:"overrides,omitempty"`
+}
+
+func (r IamUserCredentialRule) Name() string {
+	return r.IamUserName
+}
+
+// ExcessPermissionsMode determines how granted IAM actions outside of an IAM rule's required actions are reported
+// +kubebuilder:validation:Enum=Warn;Fail
+type ExcessPermissionsMode string
+
+const (
+	ExcessPermissionsWarn ExcessPermissionsMode = "Warn"
+	ExcessPermissionsFail ExcessPermissionsMode = "Fail"
+)
+
+// PolicyVersionLatestNonDefault selects the most recently created non-default version of an IAM policy
+const PolicyVersionLatestNonDefault = "LatestNonDefault"
+
+// LastAccessedCheck configures the detection of unused services via IAM service last accessed data
+type LastAccessedCheck struct {
+	// The number of days after which a granted service that has not been accessed is reported as unused.
+	// +kubebuilder:default=90
+	// +kubebuilder:validation:Minimum=1
+	UnusedDays int `json:"unusedDays" yaml:"unusedDays"`
+	// If true, unused services are reported as failures. Otherwise, they are reported as details.
+	Fail bool `json:"fail,omitempty" yaml:"fail,omitempty"`
+}
+
 type PolicyDocument struct {
 	Name       string           `json:"name" yaml:"name"`
 	Version    string           `json:"version" yaml:"version"`
@@ -152,16 +561,108 @@ func (c *Condition) String() string {
 	return fmt.Sprintf("%s: %s=%s", c.Type, c.Key, c.Values)
 }
 
+// IamExistenceRule validates that IAM service-linked roles and instance profiles exist
+type IamExistenceRule struct {
+	Name string `json:"name" yaml:"name"`
+	// Names of service-linked roles that must exist, e.g., AWSServiceRoleForElasticLoadBalancing.
+	ServiceLinkedRoles []string `json:"serviceLinkedRoles,omitempty" yaml:"serviceLinkedRoles,omitempty"`
+	// Instance profiles that must exist.
+	InstanceProfiles []InstanceProfile `json:"instanceProfiles,omitempty" yaml:"instanceProfiles,omitempty"`
+	// Overrides the auth and region used to evaluate the rule (optional)
+	Overrides *RuleOverrides `json:"overrides,omitempty" yaml:"overrides,omitempty"`
+}
+
+type InstanceProfile struct {
+	Name string `json:"name" yaml:"name"`
+	// Name of an IAM role the instance profile must contain (optional)
+	IamRoleName string `json:"iamRoleName,omitempty" yaml:"iamRoleName,omitempty"`
+}
+
+// IamQuotaRule validates that IAM entity & policy attachment quotas have sufficient headroom
+type IamQuotaRule struct {
+	Name string `json:"name" yaml:"name"`
+	// Account-wide IAM quotas, as reported by GetAccountSummary
+	AccountQuotas []IamAccountQuota `json:"accountQuotas,omitempty" yaml:"accountQuotas,omitempty"`
+	// IAM roles, users, and groups whose policy attachment quotas must have headroom
+	Principals []IamPrincipalQuota `json:"principals,omitempty" yaml:"principals,omitempty"`
+	// Overrides the auth and region used to evaluate the rule (optional)
+	Overrides *RuleOverrides `json:"overrides,omitempty" yaml:"overrides,omitempty"`
+}
+
+type IamAccountQuota struct {
+	// +kubebuilder:validation:Enum=Groups;InstanceProfiles;Policies;PolicyVersionsInUse;Roles;Users
+	Name   string `json:"name" yaml:"name"`
+	Buffer int    `json:"buffer" yaml:"buffer"`
+}
+
+type IamPrincipalQuota struct {
+	// +kubebuilder:validation:Enum=Group;Role;User
+	Type string `json:"type" yaml:"type"`
+	Name string `json:"name" yaml:"name"`
+	// The minimum number of additional managed policies that must be attachable to the principal.
+	AttachedPoliciesBuffer int `json:"attachedPoliciesBuffer,omitempty" yaml:"attachedPoliciesBuffer,omitempty"`
+	// The minimum number of characters that must remain in the principal's aggregate inline policy size quota.
+	// Inline policy size is not checked if unset.
+	InlinePolicySizeBuffer int `json:"inlinePolicySizeBuffer,omitempty" yaml:"inlinePolicySizeBuffer,omitempty"`
+}
+
+// AccountRule validates account-wide IAM security controls
+type AccountRule struct {
+	Name string `json:"name" yaml:"name"`
+	// Minimum requirements for the account password policy (optional)
+	PasswordPolicy *PasswordPolicyRequirements `json:"passwordPolicy,omitempty" yaml:"passwordPolicy,omitempty"`
+	// If true, the root user must have MFA enabled.
+	RequireRootMFA bool `json:"requireRootMfa,omitempty" yaml:"requireRootMfa,omitempty"`
+	// If true, the root user must not have any access keys.
+	DisallowRootAccessKeys bool `json:"disallowRootAccessKeys,omitempty" yaml:"disallowRootAccessKeys,omitempty"`
+	// Overrides the auth and region used to evaluate the rule (optional)
+	Overrides *RuleOverrides `json:"overrides,omitempty" yaml:"overrides,omitempty"`
+}
+
+// PasswordPolicyRequirements declares the minimum settings for an account password policy.
+// Unset fields are not checked.
+type PasswordPolicyRequirements struct {
+	// The minimum password length the policy must enforce.
+	MinimumPasswordLength int `json:"minimumPasswordLength,omitempty" yaml:"minimumPasswordLength,omitempty"`
+	// The minimum number of previous passwords the policy must prevent reuse of.
+	PasswordReusePrevention int `json:"passwordReusePrevention,omitempty" yaml:"passwordReusePrevention,omitempty"`
+	// The maximum password age, in days, the policy may allow. Passwords must expire.
+	MaxPasswordAge             int  `json:"maxPasswordAge,omitempty" yaml:"maxPasswordAge,omitempty"`
+	RequireUppercaseCharacters bool `json:"requireUppercaseCharacters,omitempty" yaml:"requireUppercaseCharacters,omitempty"`
+	RequireLowercaseCharacters bool `json:"requireLowercaseCharacters,omitempty" yaml:"requireLowercaseCharacters,omitempty"`
+	RequireNumbers             bool `json:"requireNumbers,omitempty" yaml:"requireNumbers,omitempty"`
+	RequireSymbols             bool `json:"requireSymbols,omitempty" yaml:"requireSymbols,omitempty"`
+}
+
 type ServiceQuotaRule struct {
 	Name          string         `json:"name" yaml:"name"`
 	Region        string         `json:"region" yaml:"region"`
 	ServiceCode   string         `json:"serviceCode" yaml:"serviceCode"`
 	ServiceQuotas []ServiceQuota `json:"serviceQuotas" yaml:"serviceQuotas"`
+	// Overrides the auth and region used to evaluate the rule (optional)
+	Overrides *RuleOverrides `json:"overrides,omitempty" yaml:"overrides,omitempty"`
 }
 
+// ServiceQuota identifies a service quota by name and/or quota code, and the headroom that must remain below it.
+// If several of buffer, bufferPercent, and required are set, all of them must be satisfied.
+// +kubebuilder:validation:XValidation:message="At least one of name or quotaCode must be set",rule="has(self.name) || has(self.quotaCode)"
 type ServiceQuota struct {
-	Name   string `json:"name" yaml:"name"`
-	Buffer int    `json:"buffer" yaml:"buffer"`
+	// The name of the quota, e.g., EC2-VPC Elastic IPs (optional). Quota names may be changed by AWS; prefer quotaCode.
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+	// The quota code, e.g., L-0263D0A3 (optional). If set, the quota is looked up by code and name is ignored.
+	// +kubebuilder:validation:Pattern=`^L-[0-9A-F]{8}$`
+	QuotaCode string `json:"quotaCode,omitempty" yaml:"quotaCode,omitempty"`
+	// The minimum remaining quota, in the quota's unit (optional)
+	// +kubebuilder:validation:Minimum=0
+	Buffer int `json:"buffer,omitempty" yaml:"buffer,omitempty"`
+	// The minimum remaining quota, as a percentage of the quota's value (optional)
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	BufferPercent int `json:"bufferPercent,omitempty" yaml:"bufferPercent,omitempty"`
+	// The amount of the quota that must be available, e.g., the number of resources that will be created (optional).
+	// For quotas broken out by VPC, availability zone, etc., the amount must be available in each.
+	// +kubebuilder:validation:Minimum=0
+	Required int `json:"required,omitempty" yaml:"required,omitempty"`
 }
 
 type TagRule struct {
@@ -171,6 +672,8 @@ type TagRule struct {
 	Region        string   `json:"region" yaml:"region"`
 	ResourceType  string   `json:"resourceType" yaml:"resourceType"`
 	ARNs          []string `json:"arns" yaml:"arns"`
+	// Overrides the auth and region used to evaluate the rule (optional)
+	Overrides *RuleOverrides `json:"overrides,omitempty" yaml:"overrides,omitempty"`
 }
 
 // AwsValidatorStatus defines the observed state of AwsValidator
@@ -25,13 +25,73 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AccountRule) DeepCopyInto(out *AccountRule) {
+	*out = *in
+	if in.PasswordPolicy != nil {
+		in, out := &in.PasswordPolicy, &out.PasswordPolicy
+		*out = new(PasswordPolicyRequirements)
+		**out = **in
+	}
+	if in.Overrides != nil {
+		in, out := &in.Overrides, &out.Overrides
+		*out = new(RuleOverrides)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccountRule.
+func (in *AccountRule) DeepCopy() *AccountRule {
+	if in == nil {
+		return nil
+	}
+	out := new(AccountRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AccountTargets) DeepCopyInto(out *AccountTargets) {
+	*out = *in
+	if in.AccountIDs != nil {
+		in, out := &in.AccountIDs, &out.AccountIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccountTargets.
+func (in *AccountTargets) DeepCopy() *AccountTargets {
+	if in == nil {
+		return nil
+	}
+	out := new(AccountTargets)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AwsAuth) DeepCopyInto(out *AwsAuth) {
 	*out = *in
+	if in.SharedConfig != nil {
+		in, out := &in.SharedConfig, &out.SharedConfig
+		*out = new(AwsSharedConfig)
+		**out = **in
+	}
+	if in.WebIdentity != nil {
+		in, out := &in.WebIdentity, &out.WebIdentity
+		*out = new(AwsWebIdentityAuth)
+		**out = **in
+	}
 	if in.StsAuth != nil {
 		in, out := &in.StsAuth, &out.StsAuth
 		*out = new(AwsSTSAuth)
-		**out = **in
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClientConfig != nil {
+		in, out := &in.ClientConfig, &out.ClientConfig
+		*out = new(AwsClientConfig)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
@@ -45,9 +105,53 @@ func (in *AwsAuth) DeepCopy() *AwsAuth {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AwsClientConfig) DeepCopyInto(out *AwsClientConfig) {
+	*out = *in
+	if in.ServiceEndpoints != nil {
+		in, out := &in.ServiceEndpoints, &out.ServiceEndpoints
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.CABundle != nil {
+		in, out := &in.CABundle, &out.CABundle
+		*out = new(CABundleSource)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AwsClientConfig.
+func (in *AwsClientConfig) DeepCopy() *AwsClientConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(AwsClientConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AwsSTSAuth) DeepCopyInto(out *AwsSTSAuth) {
 	*out = *in
+	if in.SessionTags != nil {
+		in, out := &in.SessionTags, &out.SessionTags
+		*out = make([]SessionTag, len(*in))
+		copy(*out, *in)
+	}
+	if in.PolicyArns != nil {
+		in, out := &in.PolicyArns, &out.PolicyArns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RoleChain != nil {
+		in, out := &in.RoleChain, &out.RoleChain
+		*out = make([]AwsSTSRole, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AwsSTSAuth.
@@ -60,6 +164,46 @@ func (in *AwsSTSAuth) DeepCopy() *AwsSTSAuth {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AwsSTSRole) DeepCopyInto(out *AwsSTSRole) {
+	*out = *in
+	if in.SessionTags != nil {
+		in, out := &in.SessionTags, &out.SessionTags
+		*out = make([]SessionTag, len(*in))
+		copy(*out, *in)
+	}
+	if in.PolicyArns != nil {
+		in, out := &in.PolicyArns, &out.PolicyArns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AwsSTSRole.
+func (in *AwsSTSRole) DeepCopy() *AwsSTSRole {
+	if in == nil {
+		return nil
+	}
+	out := new(AwsSTSRole)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AwsSharedConfig) DeepCopyInto(out *AwsSharedConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AwsSharedConfig.
+func (in *AwsSharedConfig) DeepCopy() *AwsSharedConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(AwsSharedConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AwsValidator) DeepCopyInto(out *AwsValidator) {
 	*out = *in
@@ -123,6 +267,11 @@ func (in *AwsValidatorList) DeepCopyObject() runtime.Object {
 func (in *AwsValidatorSpec) DeepCopyInto(out *AwsValidatorSpec) {
 	*out = *in
 	in.Auth.DeepCopyInto(&out.Auth)
+	if in.Accounts != nil {
+		in, out := &in.Accounts, &out.Accounts
+		*out = new(AccountTargets)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.IamRoleRules != nil {
 		in, out := &in.IamRoleRules, &out.IamRoleRules
 		*out = make([]IamRoleRule, len(*in))
@@ -151,6 +300,48 @@ func (in *AwsValidatorSpec) DeepCopyInto(out *AwsValidatorSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.IamIrsaRules != nil {
+		in, out := &in.IamIrsaRules, &out.IamIrsaRules
+		*out = make([]IamIrsaRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.IamCustomPolicyRules != nil {
+		in, out := &in.IamCustomPolicyRules, &out.IamCustomPolicyRules
+		*out = make([]IamCustomPolicyRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.IamUserCredentialRules != nil {
+		in, out := &in.IamUserCredentialRules, &out.IamUserCredentialRules
+		*out = make([]IamUserCredentialRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.IamExistenceRules != nil {
+		in, out := &in.IamExistenceRules, &out.IamExistenceRules
+		*out = make([]IamExistenceRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.IamQuotaRules != nil {
+		in, out := &in.IamQuotaRules, &out.IamQuotaRules
+		*out = make([]IamQuotaRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AccountRules != nil {
+		in, out := &in.AccountRules, &out.AccountRules
+		*out = make([]AccountRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.ServiceQuotaRules != nil {
 		in, out := &in.ServiceQuotaRules, &out.ServiceQuotaRules
 		*out = make([]ServiceQuotaRule, len(*in))
@@ -192,6 +383,36 @@ func (in *AwsValidatorStatus) DeepCopy() *AwsValidatorStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AwsWebIdentityAuth) DeepCopyInto(out *AwsWebIdentityAuth) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AwsWebIdentityAuth.
+func (in *AwsWebIdentityAuth) DeepCopy() *AwsWebIdentityAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(AwsWebIdentityAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CABundleSource) DeepCopyInto(out *CABundleSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CABundleSource.
+func (in *CABundleSource) DeepCopy() *CABundleSource {
+	if in == nil {
+		return nil
+	}
+	out := new(CABundleSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Condition) DeepCopyInto(out *Condition) {
 	*out = *in
@@ -212,6 +433,83 @@ func (in *Condition) DeepCopy() *Condition {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IamAccountQuota) DeepCopyInto(out *IamAccountQuota) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IamAccountQuota.
+func (in *IamAccountQuota) DeepCopy() *IamAccountQuota {
+	if in == nil {
+		return nil
+	}
+	out := new(IamAccountQuota)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IamCustomPolicyRule) DeepCopyInto(out *IamCustomPolicyRule) {
+	*out = *in
+	if in.PolicyDocuments != nil {
+		in, out := &in.PolicyDocuments, &out.PolicyDocuments
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Policies != nil {
+		in, out := &in.Policies, &out.Policies
+		*out = make([]PolicyDocument, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Overrides != nil {
+		in, out := &in.Overrides, &out.Overrides
+		*out = new(RuleOverrides)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IamCustomPolicyRule.
+func (in *IamCustomPolicyRule) DeepCopy() *IamCustomPolicyRule {
+	if in == nil {
+		return nil
+	}
+	out := new(IamCustomPolicyRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IamExistenceRule) DeepCopyInto(out *IamExistenceRule) {
+	*out = *in
+	if in.ServiceLinkedRoles != nil {
+		in, out := &in.ServiceLinkedRoles, &out.ServiceLinkedRoles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.InstanceProfiles != nil {
+		in, out := &in.InstanceProfiles, &out.InstanceProfiles
+		*out = make([]InstanceProfile, len(*in))
+		copy(*out, *in)
+	}
+	if in.Overrides != nil {
+		in, out := &in.Overrides, &out.Overrides
+		*out = new(RuleOverrides)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IamExistenceRule.
+func (in *IamExistenceRule) DeepCopy() *IamExistenceRule {
+	if in == nil {
+		return nil
+	}
+	out := new(IamExistenceRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IamGroupRule) DeepCopyInto(out *IamGroupRule) {
 	*out = *in
@@ -222,6 +520,11 @@ func (in *IamGroupRule) DeepCopyInto(out *IamGroupRule) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Overrides != nil {
+		in, out := &in.Overrides, &out.Overrides
+		*out = new(RuleOverrides)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IamGroupRule.
@@ -234,6 +537,38 @@ func (in *IamGroupRule) DeepCopy() *IamGroupRule {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IamIrsaRule) DeepCopyInto(out *IamIrsaRule) {
+	*out = *in
+	if in.Policies != nil {
+		in, out := &in.Policies, &out.Policies
+		*out = make([]PolicyDocument, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastAccessed != nil {
+		in, out := &in.LastAccessed, &out.LastAccessed
+		*out = new(LastAccessedCheck)
+		**out = **in
+	}
+	if in.Overrides != nil {
+		in, out := &in.Overrides, &out.Overrides
+		*out = new(RuleOverrides)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IamIrsaRule.
+func (in *IamIrsaRule) DeepCopy() *IamIrsaRule {
+	if in == nil {
+		return nil
+	}
+	out := new(IamIrsaRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IamPolicyRule) DeepCopyInto(out *IamPolicyRule) {
 	*out = *in
@@ -244,6 +579,11 @@ func (in *IamPolicyRule) DeepCopyInto(out *IamPolicyRule) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Overrides != nil {
+		in, out := &in.Overrides, &out.Overrides
+		*out = new(RuleOverrides)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IamPolicyRule.
@@ -256,6 +596,51 @@ func (in *IamPolicyRule) DeepCopy() *IamPolicyRule {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IamPrincipalQuota) DeepCopyInto(out *IamPrincipalQuota) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IamPrincipalQuota.
+func (in *IamPrincipalQuota) DeepCopy() *IamPrincipalQuota {
+	if in == nil {
+		return nil
+	}
+	out := new(IamPrincipalQuota)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IamQuotaRule) DeepCopyInto(out *IamQuotaRule) {
+	*out = *in
+	if in.AccountQuotas != nil {
+		in, out := &in.AccountQuotas, &out.AccountQuotas
+		*out = make([]IamAccountQuota, len(*in))
+		copy(*out, *in)
+	}
+	if in.Principals != nil {
+		in, out := &in.Principals, &out.Principals
+		*out = make([]IamPrincipalQuota, len(*in))
+		copy(*out, *in)
+	}
+	if in.Overrides != nil {
+		in, out := &in.Overrides, &out.Overrides
+		*out = new(RuleOverrides)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IamQuotaRule.
+func (in *IamQuotaRule) DeepCopy() *IamQuotaRule {
+	if in == nil {
+		return nil
+	}
+	out := new(IamQuotaRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IamRoleRule) DeepCopyInto(out *IamRoleRule) {
 	*out = *in
@@ -266,6 +651,16 @@ func (in *IamRoleRule) DeepCopyInto(out *IamRoleRule) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.LastAccessed != nil {
+		in, out := &in.LastAccessed, &out.LastAccessed
+		*out = new(LastAccessedCheck)
+		**out = **in
+	}
+	if in.Overrides != nil {
+		in, out := &in.Overrides, &out.Overrides
+		*out = new(RuleOverrides)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IamRoleRule.
@@ -278,6 +673,26 @@ func (in *IamRoleRule) DeepCopy() *IamRoleRule {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IamUserCredentialRule) DeepCopyInto(out *IamUserCredentialRule) {
+	*out = *in
+	if in.Overrides != nil {
+		in, out := &in.Overrides, &out.Overrides
+		*out = new(RuleOverrides)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IamUserCredentialRule.
+func (in *IamUserCredentialRule) DeepCopy() *IamUserCredentialRule {
+	if in == nil {
+		return nil
+	}
+	out := new(IamUserCredentialRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IamUserRule) DeepCopyInto(out *IamUserRule) {
 	*out = *in
@@ -288,6 +703,11 @@ func (in *IamUserRule) DeepCopyInto(out *IamUserRule) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Overrides != nil {
+		in, out := &in.Overrides, &out.Overrides
+		*out = new(RuleOverrides)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IamUserRule.
@@ -300,6 +720,51 @@ func (in *IamUserRule) DeepCopy() *IamUserRule {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstanceProfile) DeepCopyInto(out *InstanceProfile) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceProfile.
+func (in *InstanceProfile) DeepCopy() *InstanceProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(InstanceProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LastAccessedCheck) DeepCopyInto(out *LastAccessedCheck) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LastAccessedCheck.
+func (in *LastAccessedCheck) DeepCopy() *LastAccessedCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(LastAccessedCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PasswordPolicyRequirements) DeepCopyInto(out *PasswordPolicyRequirements) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PasswordPolicyRequirements.
+func (in *PasswordPolicyRequirements) DeepCopy() *PasswordPolicyRequirements {
+	if in == nil {
+		return nil
+	}
+	out := new(PasswordPolicyRequirements)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PolicyDocument) DeepCopyInto(out *PolicyDocument) {
 	*out = *in
@@ -322,6 +787,46 @@ func (in *PolicyDocument) DeepCopy() *PolicyDocument {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RuleAuth) DeepCopyInto(out *RuleAuth) {
+	*out = *in
+	if in.StsAuth != nil {
+		in, out := &in.StsAuth, &out.StsAuth
+		*out = new(AwsSTSAuth)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RuleAuth.
+func (in *RuleAuth) DeepCopy() *RuleAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(RuleAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RuleOverrides) DeepCopyInto(out *RuleOverrides) {
+	*out = *in
+	if in.Auth != nil {
+		in, out := &in.Auth, &out.Auth
+		*out = new(RuleAuth)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RuleOverrides.
+func (in *RuleOverrides) DeepCopy() *RuleOverrides {
+	if in == nil {
+		return nil
+	}
+	out := new(RuleOverrides)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServiceQuota) DeepCopyInto(out *ServiceQuota) {
 	*out = *in
@@ -345,6 +850,11 @@ func (in *ServiceQuotaRule) DeepCopyInto(out *ServiceQuotaRule) {
 		*out = make([]ServiceQuota, len(*in))
 		copy(*out, *in)
 	}
+	if in.Overrides != nil {
+		in, out := &in.Overrides, &out.Overrides
+		*out = new(RuleOverrides)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceQuotaRule.
@@ -357,6 +867,21 @@ func (in *ServiceQuotaRule) DeepCopy() *ServiceQuotaRule {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SessionTag) DeepCopyInto(out *SessionTag) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SessionTag.
+func (in *SessionTag) DeepCopy() *SessionTag {
+	if in == nil {
+		return nil
+	}
+	out := new(SessionTag)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StatementEntry) DeepCopyInto(out *StatementEntry) {
 	*out = *in
@@ -395,6 +920,11 @@ func (in *TagRule) DeepCopyInto(out *TagRule) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Overrides != nil {
+		in, out := &in.Overrides, &out.Overrides
+		*out = new(RuleOverrides)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TagRule.
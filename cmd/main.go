@@ -30,9 +30,11 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 
 	validationv1alpha1 "github.com/spectrocloud-labs/validator-plugin-aws/api/v1alpha1"
 	"github.com/spectrocloud-labs/validator-plugin-aws/internal/controller"
+	webhookv1alpha1 "github.com/spectrocloud-labs/validator-plugin-aws/internal/webhook/v1alpha1"
 	validatorv1alpha1 "github.com/spectrocloud-labs/validator/api/v1alpha1"
 	//+kubebuilder:scaffold:imports
 )
@@ -86,14 +88,27 @@ func main() {
 		os.Exit(1)
 	}
 
-	if err = (&controller.AwsValidatorReconciler{
+	reconciler := &controller.AwsValidatorReconciler{
 		Client: mgr.GetClient(),
 		Log:    ctrl.Log.WithName("controllers").WithName("AwsValidator"),
 		Scheme: mgr.GetScheme(),
-	}).SetupWithManager(mgr); err != nil {
+	}
+	if err = reconciler.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "AwsValidator")
 		os.Exit(1)
 	}
+	// Verify the plugin's own AWS permissions once at startup, in addition to each reconcile
+	if err = mgr.Add(manager.RunnableFunc(reconciler.StartupPreflight)); err != nil {
+		setupLog.Error(err, "unable to add startup preflight")
+		os.Exit(1)
+	}
+	// The validating webhook requires a serving certificate, so it's opt-in
+	if os.Getenv("ENABLE_WEBHOOKS") == "true" {
+		if err = webhookv1alpha1.SetupAwsValidatorWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "AwsValidator")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -0,0 +1,128 @@
+// Package catalog provides an embedded, versioned catalog of AWS IAM service prefixes, actions, and resource types.
+//
+// Actions and resource types are only catalogued for a subset of services. For the remaining services,
+// only the service prefix is known, so their actions and resource types are never reported as invalid.
+package catalog
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	aws_utils "github.com/spectrocloud-labs/validator-plugin-aws/internal/utils/aws"
+	str_utils "github.com/spectrocloud-labs/validator-plugin-aws/internal/utils/strings"
+)
+
+//go:embed catalog.json
+var catalogJSON []byte
+
+var (
+	defaultCatalog *Catalog
+	loadOnce       sync.Once
+)
+
+// Catalog is a catalog of AWS IAM service prefixes, actions, and resource types
+type Catalog struct {
+	Version  string             `json:"version"`
+	Services map[string]Service `json:"services"`
+}
+
+// Service is the catalog entry for a single AWS service prefix
+type Service struct {
+	Actions       []string `json:"actions,omitempty"`
+	ResourceTypes []string `json:"resourceTypes,omitempty"`
+}
+
+// Default returns the embedded IAM action catalog
+func Default() *Catalog {
+	loadOnce.Do(func() {
+		c := &Catalog{}
+		if err := json.Unmarshal(catalogJSON, c); err != nil {
+			panic(fmt.Sprintf("invalid embedded IAM action catalog: %v", err))
+		}
+		defaultCatalog = c
+	})
+	return defaultCatalog
+}
+
+// LintAction returns a description of the problem with an IAM action, or an empty string if the action is valid
+func (c *Catalog) LintAction(action string) string {
+	if action == "*" {
+		return ""
+	}
+	service, verb, ok := strings.Cut(action, ":")
+	if !ok || service == "" || verb == "" {
+		return fmt.Sprintf("malformed action %s", action)
+	}
+	svc, ok := c.Services[strings.ToLower(service)]
+	if !ok {
+		return fmt.Sprintf("unknown service prefix %s in action %s", service, action)
+	}
+	if len(svc.Actions) == 0 {
+		return ""
+	}
+	if strings.Contains(verb, "*") || strings.Contains(verb, "?") {
+		if len(c.Expand(action)) == 0 {
+			return fmt.Sprintf("action %s does not match any known %s action", action, service)
+		}
+		return ""
+	}
+	for _, a := range svc.Actions {
+		if strings.EqualFold(a, verb) {
+			return ""
+		}
+	}
+	return fmt.Sprintf("unknown action %s", action)
+}
+
+// LintResource returns a description of the problem with an IAM resource ARN, or an empty string if the resource is valid.
+// Only the resource type of ARNs for services with catalogued resource types is validated.
+func (c *Catalog) LintResource(resource string) string {
+	if !strings.HasPrefix(resource, "arn:") {
+		return ""
+	}
+	parsed, err := aws_utils.ParseARN(resource)
+	if err != nil {
+		return fmt.Sprintf("malformed resource ARN %s", resource)
+	}
+	svc, ok := c.Services[parsed.Service]
+	if !ok || len(svc.ResourceTypes) == 0 {
+		return ""
+	}
+	resourceType := parsed.Resource
+	if i := strings.IndexAny(resourceType, "/:"); i >= 0 {
+		resourceType = resourceType[:i]
+	}
+	if strings.ContainsAny(resourceType, "*?") {
+		return ""
+	}
+	for _, t := range svc.ResourceTypes {
+		if t == resourceType {
+			return ""
+		}
+	}
+	return fmt.Sprintf("unknown %s resource type %s in resource %s", parsed.Service, resourceType, resource)
+}
+
+// Expand expands an IAM action containing wildcards into the matching catalogued actions, using the catalog's casing.
+// Nil is returned if the action's service has no catalogued actions.
+func (c *Catalog) Expand(action string) []string {
+	service, verb, ok := strings.Cut(action, ":")
+	if !ok {
+		return nil
+	}
+	service = strings.ToLower(service)
+	svc, ok := c.Services[service]
+	if !ok || len(svc.Actions) == 0 {
+		return nil
+	}
+	expanded := make([]string, 0)
+	for _, a := range svc.Actions {
+		if str_utils.MatchWildcard(strings.ToLower(verb), strings.ToLower(a)) {
+			expanded = append(expanded, fmt.Sprintf("%s:%s", service, a))
+		}
+	}
+	return expanded
+}
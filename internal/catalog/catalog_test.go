@@ -0,0 +1,87 @@
+package catalog
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLintAction(t *testing.T) {
+	cs := []struct {
+		name     string
+		action   string
+		expected string
+	}{
+		{name: "admin", action: "*", expected: ""},
+		{name: "known action", action: "ec2:DescribeSubnets", expected: ""},
+		{name: "known action, case insensitive", action: "ec2:describesubnets", expected: ""},
+		{name: "known wildcard", action: "ec2:Describe*", expected: ""},
+		{name: "uncatalogued service", action: "eks:DescribeCluster", expected: ""},
+		{name: "typo", action: "ec2:DescribeSubnet", expected: "unknown action ec2:DescribeSubnet"},
+		{name: "unmatched wildcard", action: "iam:Describe*", expected: "action iam:Describe* does not match any known iam action"},
+		{name: "unknown service", action: "ec3:DescribeSubnets", expected: "unknown service prefix ec3 in action ec3:DescribeSubnets"},
+		{name: "malformed", action: "ec2", expected: "malformed action ec2"},
+	}
+	for _, c := range cs {
+		if actual := Default().LintAction(c.action); actual != c.expected {
+			t.Errorf("%s: expected %q, got %q", c.name, c.expected, actual)
+		}
+	}
+}
+
+func TestLintResource(t *testing.T) {
+	cs := []struct {
+		name     string
+		resource string
+		expected string
+	}{
+		{name: "wildcard", resource: "*", expected: ""},
+		{name: "known resource type", resource: "arn:aws:ec2:*:*:subnet/*", expected: ""},
+		{name: "wildcard resource type", resource: "arn:aws:ec2:*:*:*", expected: ""},
+		{name: "GovCloud partition", resource: "arn:aws-us-gov:iam::123456789012:role/foo", expected: ""},
+		{name: "China partition typo", resource: "arn:aws-cn:iam::123456789012:roles/foo", expected: "unknown iam resource type roles in resource arn:aws-cn:iam::123456789012:roles/foo"},
+		{name: "GovCloud partition", resource: "arn:aws-us-gov:iam::123456789012:role/foo", expected: ""},
+		{name: "China partition typo", resource: "arn:aws-cn:iam::123456789012:roles/foo", expected: "unknown iam resource type roles in resource arn:aws-cn:iam::123456789012:roles/foo"},
+		{name: "uncatalogued service", resource: "arn:*:eks:*:*:cluster/*", expected: ""},
+		{name: "typo", resource: "arn:aws:iam::123456789012:roles/foo", expected: "unknown iam resource type roles in resource arn:aws:iam::123456789012:roles/foo"},
+		{name: "malformed", resource: "arn:aws:iam", expected: "malformed resource ARN arn:aws:iam"},
+	}
+	for _, c := range cs {
+		if actual := Default().LintResource(c.resource); actual != c.expected {
+			t.Errorf("%s: expected %q, got %q", c.name, c.expected, actual)
+		}
+	}
+}
+
+func TestExpand(t *testing.T) {
+	cs := []struct {
+		name     string
+		action   string
+		expected []string
+	}{
+		{
+			name:     "wildcard suffix",
+			action:   "sts:Assume*",
+			expected: []string{"sts:AssumeRole", "sts:AssumeRoleWithSAML", "sts:AssumeRoleWithWebIdentity"},
+		},
+		{
+			name:     "wildcard prefix & single character",
+			action:   "sts:Get?ession*",
+			expected: []string{"sts:GetSessionToken"},
+		},
+		{
+			name:     "mixed case service prefix",
+			action:   "STS:assume*",
+			expected: []string{"sts:AssumeRole", "sts:AssumeRoleWithSAML", "sts:AssumeRoleWithWebIdentity"},
+		},
+		{
+			name:     "uncatalogued service",
+			action:   "eks:Describe*",
+			expected: nil,
+		},
+	}
+	for _, c := range cs {
+		if actual := Default().Expand(c.action); !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("%s: expected %v, got %v", c.name, c.expected, actual)
+		}
+	}
+}
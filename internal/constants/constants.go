@@ -3,12 +3,33 @@ package constants
 const (
 	PluginCode string = "AWS"
 
-	ValidationTypeIAMRolePolicy  string = "aws-iam-role-policy"
-	ValidationTypeIAMUserPolicy  string = "aws-iam-user-policy"
-	ValidationTypeIAMGroupPolicy string = "aws-iam-group-policy"
-	ValidationTypeIAMPolicy      string = "aws-iam-policy"
-	ValidationTypeServiceQuota   string = "aws-service-quota"
-	ValidationTypeTag            string = "aws-tag"
+	ValidationTypeIAMRolePolicy      string = "aws-iam-role-policy"
+	ValidationTypeIAMUserPolicy      string = "aws-iam-user-policy"
+	ValidationTypeIAMGroupPolicy     string = "aws-iam-group-policy"
+	ValidationTypeIAMPolicy          string = "aws-iam-policy"
+	ValidationTypeIAMIRSA            string = "aws-iam-irsa"
+	ValidationTypeIAMCustomPolicy    string = "aws-iam-custom-policy"
+	ValidationTypeIAMUserCredentials string = "aws-iam-user-credentials"
+	ValidationTypeIAMExistence       string = "aws-iam-existence"
+	ValidationTypeIAMQuota           string = "aws-iam-quota"
+	ValidationTypeAccount            string = "aws-account"
+	ValidationTypeServiceQuota       string = "aws-service-quota"
+	ValidationTypeTag                string = "aws-tag"
+	ValidationTypePreflight          string = "aws-plugin-preflight"
 
 	IAMWildcard string = "*"
+
+	// Path prefix shared by all IAM service-linked roles
+	ServiceLinkedRolePathPrefix string = "/aws-service-role/"
+
+	// Aggregate inline policy size quotas, in characters, by IAM principal type.
+	// These are not reported by GetAccountSummary, see:
+	// https://docs.aws.amazon.com/IAM/latest/UserGuide/reference_iam-quotas.html#reference_iam-quotas-entity-length
+	InlinePolicySizeQuotaRole  int = 10240
+	InlinePolicySizeQuotaUser  int = 2048
+	InlinePolicySizeQuotaGroup int = 5120
+
+	// IRSA
+	IRSAAudience         string = "sts.amazonaws.com"
+	IRSAAssumeRoleAction string = "sts:AssumeRoleWithWebIdentity"
 )
@@ -0,0 +1,212 @@
+package controller
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/spectrocloud-labs/validator-plugin-aws/api/v1alpha1"
+)
+
+func TestResolveSharedConfig(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	r := &AwsValidatorReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "shared-config", Namespace: "validator"},
+				Data: map[string][]byte{
+					"config":      []byte("[profile dev]\nregion = eu-west-1\n"),
+					"credentials": []byte("[dev]\naws_access_key_id = AKIAEXAMPLE\n"),
+					"custom":      []byte("[profile custom]\n"),
+				},
+			},
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "empty", Namespace: "validator"},
+				Data:       map[string][]byte{"other": []byte("")},
+			},
+		).Build(),
+		Log: logr.Discard(),
+	}
+	dir := sharedConfigDir("validator", "awsvalidator")
+	validator := func(sc *v1alpha1.AwsSharedConfig) *v1alpha1.AwsValidator {
+		return &v1alpha1.AwsValidator{
+			ObjectMeta: metav1.ObjectMeta{Name: "awsvalidator", Namespace: "validator"},
+			Spec:       v1alpha1.AwsValidatorSpec{Auth: v1alpha1.AwsAuth{SharedConfig: sc}},
+		}
+	}
+
+	cs := []struct {
+		name                string
+		sc                  *v1alpha1.AwsSharedConfig
+		expectedConfig      []string
+		expectedCredentials []string
+		expectedFiles       map[string]string
+		expectedErr         bool
+	}{
+		{
+			name:                "default keys",
+			sc:                  &v1alpha1.AwsSharedConfig{SecretName: "shared-config", Profile: "dev"},
+			expectedConfig:      []string{filepath.Join(dir, "config")},
+			expectedCredentials: []string{filepath.Join(dir, "credentials")},
+			expectedFiles: map[string]string{
+				"config":      "[profile dev]\nregion = eu-west-1\n",
+				"credentials": "[dev]\naws_access_key_id = AKIAEXAMPLE\n",
+			},
+		},
+		{
+			name:                "custom config key without credentials",
+			sc:                  &v1alpha1.AwsSharedConfig{SecretName: "shared-config", ConfigKey: "custom", CredentialsKey: "missing"},
+			expectedConfig:      []string{filepath.Join(dir, "config")},
+			expectedCredentials: []string{},
+			expectedFiles:       map[string]string{"config": "[profile custom]\n"},
+		},
+		{
+			name:        "no files in secret",
+			sc:          &v1alpha1.AwsSharedConfig{SecretName: "empty"},
+			expectedErr: true,
+		},
+		{
+			name:        "missing secret",
+			sc:          &v1alpha1.AwsSharedConfig{SecretName: "missing"},
+			expectedErr: true,
+		},
+		{
+			name: "auth mode switched",
+			sc:   nil,
+		},
+	}
+	for _, c := range cs {
+		resolved, err := r.resolveAuth(validator(c.sc))
+		if c.expectedErr {
+			if err == nil {
+				t.Errorf("%s: expected an error", c.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", c.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(resolved.SharedConfigFiles, c.expectedConfig) || !reflect.DeepEqual(resolved.SharedCredentialsFiles, c.expectedCredentials) {
+			t.Errorf("%s: expected files %v & %v, got %v & %v", c.name, c.expectedConfig, c.expectedCredentials, resolved.SharedConfigFiles, resolved.SharedCredentialsFiles)
+		}
+
+		files := make(map[string]string)
+		entries, _ := os.ReadDir(dir)
+		for _, e := range entries {
+			data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			files[e.Name()] = string(data)
+		}
+		if c.expectedFiles == nil {
+			if _, err := os.Stat(dir); !os.IsNotExist(err) {
+				t.Errorf("%s: expected %s to be removed", c.name, dir)
+			}
+		} else if !reflect.DeepEqual(files, c.expectedFiles) {
+			t.Errorf("%s: expected files %v, got %v", c.name, c.expectedFiles, files)
+		}
+	}
+}
+
+func TestRemoveSharedConfigFiles(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+
+	dir := sharedConfigDir("validator", "awsvalidator")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "credentials"), []byte("[default]\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	other := sharedConfigDir("validator", "other")
+	if err := os.MkdirAll(other, 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	r := &AwsValidatorReconciler{Log: logr.Discard()}
+	r.removeSharedConfigFiles("validator", "awsvalidator")
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("expected %s to be removed", dir)
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Errorf("expected %s to be kept: %v", other, err)
+	}
+
+	// removing files that don't exist is a no-op
+	r.removeSharedConfigFiles("validator", "awsvalidator")
+}
+
+func TestConfigureAuth(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	r := &AwsValidatorReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).Build(),
+		Log:    logr.Discard(),
+	}
+	validator := func(auth v1alpha1.AwsAuth) *v1alpha1.AwsValidator {
+		return &v1alpha1.AwsValidator{
+			ObjectMeta: metav1.ObjectMeta{Name: "awsvalidator", Namespace: "validator"},
+			Spec:       v1alpha1.AwsValidatorSpec{Auth: auth},
+		}
+	}
+
+	cs := []struct {
+		name        string
+		auth        v1alpha1.AwsAuth
+		expectedErr string
+	}{
+		{
+			name: "implicit",
+			auth: v1alpha1.AwsAuth{Implicit: true},
+		},
+		{
+			name:        "secret name required",
+			auth:        v1alpha1.AwsAuth{},
+			expectedErr: "auth.secretName is required",
+		},
+		{
+			name:        "missing credentials secret",
+			auth:        v1alpha1.AwsAuth{SecretName: "aws-creds"},
+			expectedErr: `failed to configure environment from secret aws-creds: secrets "aws-creds" not found`,
+		},
+		{
+			name: "missing CA bundle",
+			auth: v1alpha1.AwsAuth{
+				Implicit:     true,
+				ClientConfig: &v1alpha1.AwsClientConfig{CABundle: &v1alpha1.CABundleSource{ConfigMapName: "ca-bundle"}},
+			},
+			expectedErr: `failed to resolve AWS auth configuration: configmaps "ca-bundle" not found`,
+		},
+	}
+	for _, c := range cs {
+		_, err := r.configureAuth(validator(c.auth))
+		if c.expectedErr == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", c.name, err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != c.expectedErr {
+			t.Errorf("%s: expected error %q, got %v", c.name, c.expectedErr, err)
+		}
+	}
+}
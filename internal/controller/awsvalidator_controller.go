@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -36,7 +37,9 @@ import (
 	"github.com/spectrocloud-labs/validator-plugin-aws/api/v1alpha1"
 	"github.com/spectrocloud-labs/validator-plugin-aws/internal/constants"
 	aws_utils "github.com/spectrocloud-labs/validator-plugin-aws/internal/utils/aws"
+	"github.com/spectrocloud-labs/validator-plugin-aws/internal/validators/account"
 	"github.com/spectrocloud-labs/validator-plugin-aws/internal/validators/iam"
+	"github.com/spectrocloud-labs/validator-plugin-aws/internal/validators/preflight"
 	"github.com/spectrocloud-labs/validator-plugin-aws/internal/validators/servicequota"
 	"github.com/spectrocloud-labs/validator-plugin-aws/internal/validators/tag"
 	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
@@ -67,20 +70,11 @@ func (r *AwsValidatorReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	if err := r.Get(ctx, req.NamespacedName, validator); err != nil {
 		if !apierrs.IsNotFound(err) {
 			l.Error(err, "failed to fetch AwsValidator")
-		}
-		return ctrl.Result{}, client.IgnoreNotFound(err)
-	}
-
-	// Configure AWS environment variable credentials from a secret, if applicable
-	if !validator.Spec.Auth.Implicit {
-		if validator.Spec.Auth.SecretName == "" {
-			l.Error(ErrSecretNameRequired, "failed to reconcile AwsValidator with empty auth.secretName")
-			return ctrl.Result{}, ErrSecretNameRequired
-		}
-		if err := r.envFromSecret(validator.Spec.Auth.SecretName, req.Namespace); err != nil {
-			l.Error(err, "failed to configure environment from secret")
 			return ctrl.Result{}, err
 		}
+		// The AwsValidator was deleted, so remove its shared config files, if any
+		r.removeSharedConfigFiles(req.Namespace, req.Name)
+		return ctrl.Result{}, nil
 	}
 
 	// Get the active validator's validation result
@@ -100,7 +94,9 @@ func (r *AwsValidatorReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		if !apierrs.IsNotFound(err) {
 			l.Error(err, "unexpected error getting ValidationResult")
 		}
-		if err := vres.HandleNewValidationResult(ctx, r.Client, p, buildValidationResult(validator), r.Log); err != nil {
+		// Resolve the target accounts first, so that the ValidationResult expects a result per rule in each account
+		accountIDs := r.initialTargetAccounts(validator)
+		if err := vres.HandleNewValidationResult(ctx, r.Client, p, buildValidationResult(validator, expectedResults(validator.Spec, accountIDs)), r.Log); err != nil {
 			return ctrl.Result{}, err
 		}
 		return ctrl.Result{RequeueAfter: time.Millisecond}, nil
@@ -114,48 +110,253 @@ func (r *AwsValidatorReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		ValidationRuleErrors:  make([]error, 0, vr.Spec.ExpectedResults),
 	}
 
-	// IAM rules
-	awsApi, err := aws_utils.NewAwsApi(r.Log, validator.Spec.Auth, validator.Spec.DefaultRegion)
+	// Configure the AWS credentials and resolve the CA bundle and shared config files for the AWS service clients.
+	// If that fails, no rule can be evaluated, so each rule is reported as failed.
+	resolved, err := r.configureAuth(validator)
 	if err != nil {
-		r.Log.V(0).Error(err, "failed to get AWS client")
-	} else {
-		iamRuleService := iam.NewIAMRuleService(r.Log, awsApi.IAM)
+		l.Error(err, "failed to configure AWS auth")
+		for _, ref := range ruleRefs(validator.Spec) {
+			resp.AddResult(clientFailureResult(ref, err), nil)
+		}
+		vr.Spec.ExpectedResults = len(resp.ValidationRuleResults)
+		if err := vres.SafeUpdateValidationResult(ctx, p, vr, resp, r.Log); err != nil {
+			return ctrl.Result{}, err
+		}
+		r.Log.V(0).Info("Requeuing for re-validation in two minutes.", "name", req.Name, "namespace", req.Namespace)
+		return ctrl.Result{RequeueAfter: time.Second * 120}, nil
+	}
 
-		for _, rule := range validator.Spec.IamRoleRules {
-			vrr, err := iamRuleService.ReconcileIAMRoleRule(rule)
+	// Verify that the AWS credentials belong to the expected account & partition, if any
+	clients := r.newAwsClients(validator, resolved)
+	awsApi, err := clients.get(nil, validator.Spec.DefaultRegion)
+	if err != nil {
+		r.Log.V(0).Error(err, "failed to get AWS client")
+	} else if mismatch, err := preflight.NewPreflightService(r.Log, awsApi.STS, awsApi.IAM).CheckCallerIdentity(validator.Spec.Auth); err != nil || mismatch != "" {
+		for _, ref := range ruleRefs(validator.Spec) {
 			if err != nil {
-				r.Log.V(0).Error(err, "failed to reconcile IAM role rule")
+				resp.AddResult(clientFailureResult(ref, err), nil)
+				continue
 			}
-			resp.AddResult(vrr, err)
+			resp.AddResult(failedRuleResult(ref, "AWS credentials do not belong to the expected account/partition", mismatch), nil)
 		}
-		for _, rule := range validator.Spec.IamUserRules {
-			vrr, err := iamRuleService.ReconcileIAMUserRule(rule)
-			if err != nil {
-				r.Log.V(0).Error(err, "failed to reconcile IAM user rule")
-			}
-			resp.AddResult(vrr, err)
+		if err == nil {
+			r.Log.V(0).Info("AWS credentials do not belong to the expected account/partition", "mismatch", mismatch)
 		}
-		for _, rule := range validator.Spec.IamGroupRules {
-			vrr, err := iamRuleService.ReconcileIAMGroupRule(rule)
-			if err != nil {
-				r.Log.V(0).Error(err, "failed to reconcile IAM group rule")
+		vr.Spec.ExpectedResults = len(resp.ValidationRuleResults)
+		if err := vres.SafeUpdateValidationResult(ctx, p, vr, resp, r.Log); err != nil {
+			return ctrl.Result{}, err
+		}
+		r.Log.V(0).Info("Requeuing for re-validation in two minutes.", "name", req.Name, "namespace", req.Namespace)
+		return ctrl.Result{RequeueAfter: time.Second * 120}, nil
+	}
+
+	if validator.Spec.Accounts == nil {
+		r.reconcileRules(&resp, validator.Spec, clients)
+	} else {
+		// Run every rule in each target account
+		accountIDs, err := r.targetAccounts(awsApi, validator.Spec.Accounts)
+		if err != nil {
+			r.Log.V(0).Error(err, "failed to determine target accounts")
+			for _, ref := range ruleRefs(validator.Spec) {
+				resp.AddResult(failedRuleResult(ref, "Failed to determine target accounts", err.Error()), nil)
+			}
+			vr.Spec.ExpectedResults = len(resp.ValidationRuleResults)
+		} else {
+			vr.Spec.ExpectedResults = expectedResults(validator.Spec, accountIDs)
+			partition := validator.Spec.Auth.ExpectedPartition
+			if partition == "" {
+				partition = aws_utils.PartitionForRegion(validator.Spec.DefaultRegion)
+			}
+			for _, accountID := range accountIDs {
+				accountResp := types.ValidationResponse{}
+				r.reconcileRules(&accountResp, validator.Spec, clients.forAccount(validator.Spec.Accounts, partition, accountID))
+				for i, vrr := range accountResp.ValidationRuleResults {
+					withAccountID(vrr, accountID)
+					resp.AddResult(vrr, accountResp.ValidationRuleErrors[i])
+				}
 			}
-			resp.AddResult(vrr, err)
 		}
-		for _, rule := range validator.Spec.IamPolicyRules {
-			vrr, err := iamRuleService.ReconcileIAMPolicyRule(rule)
+	}
+
+	// Patch the ValidationResult with the latest ValidationRuleResults
+	if err := vres.SafeUpdateValidationResult(ctx, p, vr, resp, r.Log); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	r.Log.V(0).Info("Requeuing for re-validation in two minutes.", "name", req.Name, "namespace", req.Namespace)
+	return ctrl.Result{RequeueAfter: time.Second * 120}, nil
+}
+
+// reconcileRules reconciles each rule in an AwsValidator spec and adds the results to a ValidationResponse.
+// Each rule is evaluated with the AwsApi for its auth & region overrides, if any.
+func (r *AwsValidatorReconciler) reconcileRules(resp *types.ValidationResponse, spec v1alpha1.AwsValidatorSpec, clients *awsClients) {
+
+	// Plugin credentials preflight
+	if spec.RuleResultCount() > 0 {
+		awsApi, err := clients.get(nil, spec.DefaultRegion)
+		if err != nil {
+			r.Log.V(0).Error(err, "failed to get AWS client")
+			resp.AddResult(clientFailureResult(ruleRef{preflight.RuleName, constants.ValidationTypePreflight}, err), nil)
+		} else {
+			preflightService := preflight.NewPreflightService(r.Log, awsApi.STS, awsApi.IAM)
+			vrr, err := preflightService.ReconcilePreflight(spec, awsApi.CredentialSource(context.Background()))
 			if err != nil {
-				r.Log.V(0).Error(err, "failed to reconcile IAM policy rule")
+				r.Log.V(0).Error(err, "failed to reconcile plugin credentials preflight")
 			}
-			resp.AddResult(vrr, err)
+			resp.AddResult(classifyRuleError(vrr, err))
 		}
 	}
 
+	// IAM rules
+	newIAMRuleService := func(o *v1alpha1.RuleOverrides) (*iam.IAMRuleService, error) {
+		awsApi, err := clients.get(o.AuthOverride(), o.RegionOr(spec.DefaultRegion))
+		if err != nil {
+			return nil, err
+		}
+		return iam.NewIAMRuleService(r.Log, awsApi.IAM, awsApi.S3, awsApi.KMS, awsApi.SQS), nil
+	}
+
+	for _, rule := range spec.IamRoleRules {
+		iamRuleService, err := newIAMRuleService(rule.Overrides)
+		if err != nil {
+			r.Log.V(0).Error(err, "failed to get AWS client")
+			resp.AddResult(clientFailureResult(ruleRef{rule.Name(), constants.ValidationTypeIAMRolePolicy}, err), nil)
+			continue
+		}
+		vrr, err := iamRuleService.ReconcileIAMRoleRule(rule)
+		if err != nil {
+			r.Log.V(0).Error(err, "failed to reconcile IAM role rule")
+		}
+		resp.AddResult(classifyRuleError(vrr, err))
+	}
+	for _, rule := range spec.IamUserRules {
+		iamRuleService, err := newIAMRuleService(rule.Overrides)
+		if err != nil {
+			r.Log.V(0).Error(err, "failed to get AWS client")
+			resp.AddResult(clientFailureResult(ruleRef{rule.Name(), constants.ValidationTypeIAMUserPolicy}, err), nil)
+			continue
+		}
+		vrr, err := iamRuleService.ReconcileIAMUserRule(rule)
+		if err != nil {
+			r.Log.V(0).Error(err, "failed to reconcile IAM user rule")
+		}
+		resp.AddResult(classifyRuleError(vrr, err))
+	}
+	for _, rule := range spec.IamGroupRules {
+		iamRuleService, err := newIAMRuleService(rule.Overrides)
+		if err != nil {
+			r.Log.V(0).Error(err, "failed to get AWS client")
+			resp.AddResult(clientFailureResult(ruleRef{rule.Name(), constants.ValidationTypeIAMGroupPolicy}, err), nil)
+			continue
+		}
+		vrr, err := iamRuleService.ReconcileIAMGroupRule(rule)
+		if err != nil {
+			r.Log.V(0).Error(err, "failed to reconcile IAM group rule")
+		}
+		resp.AddResult(classifyRuleError(vrr, err))
+	}
+	for _, rule := range spec.IamPolicyRules {
+		iamRuleService, err := newIAMRuleService(rule.Overrides)
+		if err != nil {
+			r.Log.V(0).Error(err, "failed to get AWS client")
+			resp.AddResult(clientFailureResult(ruleRef{rule.Name(), constants.ValidationTypeIAMPolicy}, err), nil)
+			continue
+		}
+		vrr, err := iamRuleService.ReconcileIAMPolicyRule(rule)
+		if err != nil {
+			r.Log.V(0).Error(err, "failed to reconcile IAM policy rule")
+		}
+		resp.AddResult(classifyRuleError(vrr, err))
+	}
+	for _, rule := range spec.IamIrsaRules {
+		iamRuleService, err := newIAMRuleService(rule.Overrides)
+		if err != nil {
+			r.Log.V(0).Error(err, "failed to get AWS client")
+			resp.AddResult(clientFailureResult(ruleRef{rule.Name(), constants.ValidationTypeIAMIRSA}, err), nil)
+			continue
+		}
+		vrr, err := iamRuleService.ReconcileIAMIRSARule(rule)
+		if err != nil {
+			r.Log.V(0).Error(err, "failed to reconcile IAM IRSA rule")
+		}
+		resp.AddResult(classifyRuleError(vrr, err))
+	}
+	for _, rule := range spec.IamCustomPolicyRules {
+		iamRuleService, err := newIAMRuleService(rule.Overrides)
+		if err != nil {
+			r.Log.V(0).Error(err, "failed to get AWS client")
+			resp.AddResult(clientFailureResult(ruleRef{rule.Name(), constants.ValidationTypeIAMCustomPolicy}, err), nil)
+			continue
+		}
+		vrr, err := iamRuleService.ReconcileIAMCustomPolicyRule(rule)
+		if err != nil {
+			r.Log.V(0).Error(err, "failed to reconcile IAM custom policy rule")
+		}
+		resp.AddResult(classifyRuleError(vrr, err))
+	}
+	for _, rule := range spec.IamUserCredentialRules {
+		iamRuleService, err := newIAMRuleService(rule.Overrides)
+		if err != nil {
+			r.Log.V(0).Error(err, "failed to get AWS client")
+			resp.AddResult(clientFailureResult(ruleRef{rule.Name(), constants.ValidationTypeIAMUserCredentials}, err), nil)
+			continue
+		}
+		vrr, err := iamRuleService.ReconcileIAMUserCredentialRule(rule)
+		if err != nil {
+			r.Log.V(0).Error(err, "failed to reconcile IAM user credential rule")
+		}
+		resp.AddResult(classifyRuleError(vrr, err))
+	}
+	for _, rule := range spec.IamExistenceRules {
+		iamRuleService, err := newIAMRuleService(rule.Overrides)
+		if err != nil {
+			r.Log.V(0).Error(err, "failed to get AWS client")
+			resp.AddResult(clientFailureResult(ruleRef{rule.Name, constants.ValidationTypeIAMExistence}, err), nil)
+			continue
+		}
+		vrr, err := iamRuleService.ReconcileIAMExistenceRule(rule)
+		if err != nil {
+			r.Log.V(0).Error(err, "failed to reconcile IAM existence rule")
+		}
+		resp.AddResult(classifyRuleError(vrr, err))
+	}
+	for _, rule := range spec.IamQuotaRules {
+		iamRuleService, err := newIAMRuleService(rule.Overrides)
+		if err != nil {
+			r.Log.V(0).Error(err, "failed to get AWS client")
+			resp.AddResult(clientFailureResult(ruleRef{rule.Name, constants.ValidationTypeIAMQuota}, err), nil)
+			continue
+		}
+		vrr, err := iamRuleService.ReconcileIAMQuotaRule(rule)
+		if err != nil {
+			r.Log.V(0).Error(err, "failed to reconcile IAM quota rule")
+		}
+		resp.AddResult(classifyRuleError(vrr, err))
+	}
+
+	// Account rules
+	for _, rule := range spec.AccountRules {
+		awsApi, err := clients.get(rule.Overrides.AuthOverride(), rule.Overrides.RegionOr(spec.DefaultRegion))
+		if err != nil {
+			r.Log.V(0).Error(err, "failed to get AWS client")
+			resp.AddResult(clientFailureResult(ruleRef{rule.Name, constants.ValidationTypeAccount}, err), nil)
+			continue
+		}
+		accountRuleService := account.NewAccountRuleService(r.Log, awsApi.IAM)
+		vrr, err := accountRuleService.ReconcileAccountRule(rule)
+		if err != nil {
+			r.Log.V(0).Error(err, "failed to reconcile account rule")
+		}
+		resp.AddResult(classifyRuleError(vrr, err))
+	}
+
 	// Service Quota rules
-	for _, rule := range validator.Spec.ServiceQuotaRules {
-		awsApi, err := aws_utils.NewAwsApi(r.Log, validator.Spec.Auth, rule.Region)
+	for _, rule := range spec.ServiceQuotaRules {
+		rule.Region = rule.Overrides.RegionOr(rule.Region)
+		awsApi, err := clients.get(rule.Overrides.AuthOverride(), rule.Region)
 		if err != nil {
-			r.Log.V(0).Error(err, "failed to reconcile Service Quota rule")
+			r.Log.V(0).Error(err, "failed to get AWS client")
+			resp.AddResult(clientFailureResult(ruleRef{rule.Name, constants.ValidationTypeServiceQuota}, err), nil)
 			continue
 		}
 		svcQuotaService := servicequota.NewServiceQuotaRuleService(
@@ -165,19 +366,22 @@ func (r *AwsValidatorReconciler) Reconcile(ctx context.Context, req ctrl.Request
 			awsApi.ELB,
 			awsApi.ELBV2,
 			awsApi.SQ,
+			awsApi.CW,
 		)
 		vrr, err := svcQuotaService.ReconcileServiceQuotaRule(rule)
 		if err != nil {
 			r.Log.V(0).Error(err, "failed to reconcile Service Quota rule")
 		}
-		resp.AddResult(vrr, err)
+		resp.AddResult(classifyRuleError(vrr, err))
 	}
 
 	// Tag rules
-	for _, rule := range validator.Spec.TagRules {
-		awsApi, err := aws_utils.NewAwsApi(r.Log, validator.Spec.Auth, rule.Region)
+	for _, rule := range spec.TagRules {
+		rule.Region = rule.Overrides.RegionOr(rule.Region)
+		awsApi, err := clients.get(rule.Overrides.AuthOverride(), rule.Region)
 		if err != nil {
-			r.Log.V(0).Error(err, "failed to reconcile Tag rule")
+			r.Log.V(0).Error(err, "failed to get AWS client")
+			resp.AddResult(clientFailureResult(ruleRef{rule.Name, constants.ValidationTypeTag}, err), nil)
 			continue
 		}
 		tagRuleService := tag.NewTagRuleService(r.Log, awsApi.EC2)
@@ -185,16 +389,38 @@ func (r *AwsValidatorReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		if err != nil {
 			r.Log.V(0).Error(err, "failed to reconcile Tag rule")
 		}
-		resp.AddResult(vrr, err)
+		resp.AddResult(classifyRuleError(vrr, err))
 	}
+}
 
-	// Patch the ValidationResult with the latest ValidationRuleResults
-	if err := vres.SafeUpdateValidationResult(ctx, p, vr, resp, r.Log); err != nil {
-		return ctrl.Result{}, err
+// targetAccounts returns the IDs of the accounts that an AwsValidator's rules run against
+func (r *AwsValidatorReconciler) targetAccounts(awsApi *aws_utils.AwsApi, targets *v1alpha1.AccountTargets) ([]string, error) {
+	if awsApi == nil {
+		if targets.OrganizationalUnitID != "" {
+			return nil, errors.New("failed to get AWS client to enumerate organizational unit accounts")
+		}
+		return aws_utils.TargetAccounts(context.Background(), nil, targets)
 	}
+	return aws_utils.TargetAccounts(context.Background(), awsApi.ORG, targets)
+}
 
-	r.Log.V(0).Info("Requeuing for re-validation in two minutes.", "name", req.Name, "namespace", req.Namespace)
-	return ctrl.Result{RequeueAfter: time.Second * 120}, nil
+// configureAuth configures AWS environment variable credentials from an AwsValidator's auth.secretName, if applicable,
+// and resolves the inputs of its AwsAuth that are stored in Kubernetes objects
+func (r *AwsValidatorReconciler) configureAuth(validator *v1alpha1.AwsValidator) (aws_utils.ResolvedAuth, error) {
+	auth := validator.Spec.Auth
+	if !auth.Implicit && auth.SharedConfig == nil && auth.WebIdentity == nil {
+		if auth.SecretName == "" {
+			return aws_utils.ResolvedAuth{}, ErrSecretNameRequired
+		}
+		if err := r.envFromSecret(auth.SecretName, validator.Namespace); err != nil {
+			return aws_utils.ResolvedAuth{}, fmt.Errorf("failed to configure environment from secret %s: %w", auth.SecretName, err)
+		}
+	}
+	resolved, err := r.resolveAuth(validator)
+	if err != nil {
+		return resolved, fmt.Errorf("failed to resolve AWS auth configuration: %w", err)
+	}
+	return resolved, nil
 }
 
 // envFromSecret sets environment variables from a secret to configure AWS credentials
@@ -216,6 +442,121 @@ func (r *AwsValidatorReconciler) envFromSecret(name, namespace string) error {
 	return nil
 }
 
+// resolveAuth resolves the inputs of an AwsValidator's AwsAuth that are stored in Kubernetes objects
+func (r *AwsValidatorReconciler) resolveAuth(validator *v1alpha1.AwsValidator) (aws_utils.ResolvedAuth, error) {
+	resolved := aws_utils.ResolvedAuth{}
+	caBundle, err := r.caBundle(validator.Spec.Auth.ClientConfig, validator.Namespace)
+	if err != nil {
+		return resolved, err
+	}
+	resolved.CABundle = caBundle
+
+	sc := validator.Spec.Auth.SharedConfig
+	if sc == nil {
+		// Remove any shared config files written before the AwsValidator switched to another auth mode
+		r.removeSharedConfigFiles(validator.Namespace, validator.Name)
+		return resolved, nil
+	}
+	resolved.SharedConfigFiles, resolved.SharedCredentialsFiles, err = r.sharedConfigFiles(sc, validator.Namespace, validator.Name)
+	if err != nil {
+		return resolved, err
+	}
+	return resolved, nil
+}
+
+// sharedConfigFiles writes the shared config & credentials files from an AwsSharedConfig's Secret to a per-validator
+// directory and returns their paths
+func (r *AwsValidatorReconciler) sharedConfigFiles(sc *v1alpha1.AwsSharedConfig, namespace, name string) ([]string, []string, error) {
+	nn := ktypes.NamespacedName{Name: sc.SecretName, Namespace: namespace}
+	secret := &corev1.Secret{}
+	if err := r.Get(context.Background(), nn, secret); err != nil {
+		return nil, nil, err
+	}
+
+	// Start from an empty directory so that files for keys removed from the Secret aren't left behind
+	dir := sharedConfigDir(namespace, name)
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, nil, err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, nil, err
+	}
+	write := func(key, defaultKey string) ([]string, error) {
+		if key == "" {
+			key = defaultKey
+		}
+		data, ok := secret.Data[key]
+		if !ok {
+			return []string{}, nil
+		}
+		path := filepath.Join(dir, defaultKey)
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			return nil, err
+		}
+		return []string{path}, nil
+	}
+
+	configFiles, err := write(sc.ConfigKey, "config")
+	if err != nil {
+		return nil, nil, err
+	}
+	credentialsFiles, err := write(sc.CredentialsKey, "credentials")
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(configFiles) == 0 && len(credentialsFiles) == 0 {
+		return nil, nil, fmt.Errorf("secret %s contains neither a shared config nor a shared credentials file", sc.SecretName)
+	}
+	r.Log.Info("Wrote AWS shared config files", "secret", sc.SecretName, "dir", dir)
+	return configFiles, credentialsFiles, nil
+}
+
+// removeSharedConfigFiles removes the shared config & credentials files written for an AwsValidator, if any
+func (r *AwsValidatorReconciler) removeSharedConfigFiles(namespace, name string) {
+	if err := os.RemoveAll(sharedConfigDir(namespace, name)); err != nil {
+		r.Log.V(0).Error(err, "failed to remove AWS shared config files", "namespace", namespace, "name", name)
+	}
+}
+
+// sharedConfigDir returns the directory that an AwsValidator's shared config & credentials files are written to
+func sharedConfigDir(namespace, name string) string {
+	return filepath.Join(os.TempDir(), "validator-plugin-aws", namespace, name)
+}
+
+// caBundle returns the PEM-encoded CA bundle referenced by an AwsClientConfig, if any
+func (r *AwsValidatorReconciler) caBundle(cc *v1alpha1.AwsClientConfig, namespace string) ([]byte, error) {
+	if cc == nil || cc.CABundle == nil {
+		return nil, nil
+	}
+	source := cc.CABundle
+	key := source.Key
+	if key == "" {
+		key = "ca.crt"
+	}
+
+	if source.SecretName != "" {
+		nn := ktypes.NamespacedName{Name: source.SecretName, Namespace: namespace}
+		secret := &corev1.Secret{}
+		if err := r.Get(context.Background(), nn, secret); err != nil {
+			return nil, err
+		}
+		if bundle, ok := secret.Data[key]; ok {
+			return bundle, nil
+		}
+		return nil, fmt.Errorf("key %s not found in CA bundle secret %s", key, source.SecretName)
+	}
+
+	nn := ktypes.NamespacedName{Name: source.ConfigMapName, Namespace: namespace}
+	configMap := &corev1.ConfigMap{}
+	if err := r.Get(context.Background(), nn, configMap); err != nil {
+		return nil, err
+	}
+	if bundle, ok := configMap.Data[key]; ok {
+		return []byte(bundle), nil
+	}
+	return nil, fmt.Errorf("key %s not found in CA bundle configmap %s", key, source.ConfigMapName)
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *AwsValidatorReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
@@ -223,7 +564,39 @@ func (r *AwsValidatorReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Complete(r)
 }
 
-func buildValidationResult(validator *v1alpha1.AwsValidator) *vapi.ValidationResult {
+// initialTargetAccounts resolves the target accounts of an AwsValidator that targets an organizational unit before its
+// ValidationResult is created. Nil is returned for other AwsValidators, or if the accounts can't be resolved yet,
+// in which case the failure is reported when the AwsValidator's rules are reconciled.
+func (r *AwsValidatorReconciler) initialTargetAccounts(validator *v1alpha1.AwsValidator) []string {
+	if validator.Spec.Accounts == nil || validator.Spec.Accounts.OrganizationalUnitID == "" {
+		return nil
+	}
+	resolved, err := r.configureAuth(validator)
+	if err != nil {
+		return nil
+	}
+	awsApi, err := r.newAwsClients(validator, resolved).get(nil, validator.Spec.DefaultRegion)
+	if err != nil {
+		return nil
+	}
+	accountIDs, err := r.targetAccounts(awsApi, validator.Spec.Accounts)
+	if err != nil {
+		r.Log.V(0).Error(err, "failed to determine target accounts", "name", validator.Name, "namespace", validator.Namespace)
+		return nil
+	}
+	return accountIDs
+}
+
+// expectedResults returns the number of ValidationRuleResults expected for an AwsValidator spec in its resolved target
+// accounts. If the accounts are unknown, only the accounts listed in the spec are counted.
+func expectedResults(spec v1alpha1.AwsValidatorSpec, accountIDs []string) int {
+	if spec.Accounts == nil || accountIDs == nil {
+		return spec.ResultCount()
+	}
+	return spec.RuleResultCount() * len(accountIDs)
+}
+
+func buildValidationResult(validator *v1alpha1.AwsValidator, expectedResults int) *vapi.ValidationResult {
 	return &vapi.ValidationResult{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      validationResultName(validator),
@@ -240,7 +613,7 @@ func buildValidationResult(validator *v1alpha1.AwsValidator) *vapi.ValidationRes
 		},
 		Spec: vapi.ValidationResultSpec{
 			Plugin:          constants.PluginCode,
-			ExpectedResults: validator.Spec.ResultCount(),
+			ExpectedResults: expectedResults,
 		},
 	}
 }
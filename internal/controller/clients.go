@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	ktypes "k8s.io/apimachinery/pkg/types"
+
+	"github.com/spectrocloud-labs/validator-plugin-aws/api/v1alpha1"
+	aws_utils "github.com/spectrocloud-labs/validator-plugin-aws/internal/utils/aws"
+)
+
+// awsClients creates the AwsApis used to evaluate an AwsValidator's rules, reusing one AwsApi per auth override & region
+type awsClients struct {
+	r         *AwsValidatorReconciler
+	auth      v1alpha1.AwsAuth
+	resolved  aws_utils.ResolvedAuth
+	namespace string
+
+	// The account that the rules run against and its partition, if the AwsValidator targets multiple accounts
+	accounts  *v1alpha1.AccountTargets
+	accountID string
+	partition string
+
+	cache map[string]awsClientsEntry
+}
+
+type awsClientsEntry struct {
+	awsApi *aws_utils.AwsApi
+	err    error
+}
+
+// newAwsClients creates an awsClients for an AwsValidator's rules in a single account
+func (r *AwsValidatorReconciler) newAwsClients(validator *v1alpha1.AwsValidator, resolved aws_utils.ResolvedAuth) *awsClients {
+	return &awsClients{
+		r:         r,
+		auth:      validator.Spec.Auth,
+		resolved:  resolved,
+		namespace: validator.Namespace,
+		cache:     make(map[string]awsClientsEntry),
+	}
+}
+
+// forAccount returns an awsClients for the same AwsValidator's rules in a target account
+func (c *awsClients) forAccount(accounts *v1alpha1.AccountTargets, partition, accountID string) *awsClients {
+	return &awsClients{
+		r:         c.r,
+		auth:      c.auth,
+		resolved:  c.resolved,
+		namespace: c.namespace,
+		accounts:  accounts,
+		accountID: accountID,
+		partition: partition,
+		cache:     make(map[string]awsClientsEntry),
+	}
+}
+
+// get returns the AwsApi for a rule's auth override (if any) in a region
+func (c *awsClients) get(override *v1alpha1.RuleAuth, region string) (*aws_utils.AwsApi, error) {
+	key, err := json.Marshal(struct {
+		Auth   *v1alpha1.RuleAuth
+		Region string
+	}{override, region})
+	if err != nil {
+		return nil, err
+	}
+	if entry, ok := c.cache[string(key)]; ok {
+		return entry.awsApi, entry.err
+	}
+
+	awsApi, err := c.newAwsApi(override, region)
+	c.cache[string(key)] = awsClientsEntry{awsApi, err}
+	return awsApi, err
+}
+
+func (c *awsClients) newAwsApi(override *v1alpha1.RuleAuth, region string) (*aws_utils.AwsApi, error) {
+	auth := aws_utils.OverrideAuth(c.auth, override)
+	if c.accounts != nil {
+		auth = aws_utils.AccountAuth(auth, c.accounts, c.partition, c.accountID)
+	}
+
+	resolved := c.resolved
+	if override != nil && override.SecretName != "" {
+		nn := ktypes.NamespacedName{Name: override.SecretName, Namespace: c.namespace}
+		secret := &corev1.Secret{}
+		if err := c.r.Get(context.Background(), nn, secret); err != nil {
+			return nil, fmt.Errorf("failed to get auth override secret %s: %w", override.SecretName, err)
+		}
+		creds, err := aws_utils.SecretCredentials(secret.Data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid credentials in secret %s: %w", override.SecretName, err)
+		}
+		resolved.SharedConfigFiles, resolved.SharedCredentialsFiles = nil, nil
+		resolved.Credentials = creds
+	}
+	awsApi, err := aws_utils.NewAwsApi(c.r.Log, auth, region, resolved)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AWS client: %w", err)
+	}
+	// Surface credential errors, e.g., a role that can't be assumed, once per AwsApi rather than from each AWS API call
+	if err := awsApi.RetrieveCredentials(context.Background()); err != nil {
+		return nil, fmt.Errorf("failed to retrieve AWS credentials: %w", err)
+	}
+	return awsApi, nil
+}
@@ -0,0 +1,132 @@
+package controller
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-aws/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-aws/internal/constants"
+	aws_utils "github.com/spectrocloud-labs/validator-plugin-aws/internal/utils/aws"
+	"github.com/spectrocloud-labs/validator-plugin-aws/internal/validators/preflight"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
+	"github.com/spectrocloud-labs/validator/pkg/types"
+)
+
+// ruleRef identifies the ValidationRuleResult reported for a rule
+type ruleRef struct {
+	Name           string
+	ValidationType string
+}
+
+// ruleRefs returns a reference to each ValidationRuleResult expected for an AwsValidator spec in a single account
+func ruleRefs(spec v1alpha1.AwsValidatorSpec) []ruleRef {
+	refs := make([]ruleRef, 0, spec.RuleResultCount())
+	if spec.RuleResultCount() == 0 {
+		return refs
+	}
+	refs = append(refs, ruleRef{preflight.RuleName, constants.ValidationTypePreflight})
+	for _, r := range spec.IamRoleRules {
+		refs = append(refs, ruleRef{r.Name(), constants.ValidationTypeIAMRolePolicy})
+	}
+	for _, r := range spec.IamUserRules {
+		refs = append(refs, ruleRef{r.Name(), constants.ValidationTypeIAMUserPolicy})
+	}
+	for _, r := range spec.IamGroupRules {
+		refs = append(refs, ruleRef{r.Name(), constants.ValidationTypeIAMGroupPolicy})
+	}
+	for _, r := range spec.IamPolicyRules {
+		refs = append(refs, ruleRef{r.Name(), constants.ValidationTypeIAMPolicy})
+	}
+	for _, r := range spec.IamIrsaRules {
+		refs = append(refs, ruleRef{r.Name(), constants.ValidationTypeIAMIRSA})
+	}
+	for _, r := range spec.IamCustomPolicyRules {
+		refs = append(refs, ruleRef{r.Name(), constants.ValidationTypeIAMCustomPolicy})
+	}
+	for _, r := range spec.IamUserCredentialRules {
+		refs = append(refs, ruleRef{r.Name(), constants.ValidationTypeIAMUserCredentials})
+	}
+	for _, r := range spec.IamExistenceRules {
+		refs = append(refs, ruleRef{r.Name, constants.ValidationTypeIAMExistence})
+	}
+	for _, r := range spec.IamQuotaRules {
+		refs = append(refs, ruleRef{r.Name, constants.ValidationTypeIAMQuota})
+	}
+	for _, r := range spec.AccountRules {
+		refs = append(refs, ruleRef{r.Name, constants.ValidationTypeAccount})
+	}
+	for _, r := range spec.ServiceQuotaRules {
+		refs = append(refs, ruleRef{r.Name, constants.ValidationTypeServiceQuota})
+	}
+	for _, r := range spec.TagRules {
+		refs = append(refs, ruleRef{r.Name, constants.ValidationTypeTag})
+	}
+	return refs
+}
+
+// failedRuleResult builds a failed ValidationRuleResult for a rule that could not be evaluated
+func failedRuleResult(ref ruleRef, message string, failures ...string) *types.ValidationRuleResult {
+	state := vapi.ValidationFailed
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Failures = failures
+	latestCondition.Message = message
+	latestCondition.Status = corev1.ConditionFalse
+	latestCondition.ValidationRule = fmt.Sprintf("%s-%s", vapiconstants.ValidationRulePrefix, ref.Name)
+	latestCondition.ValidationType = ref.ValidationType
+	return &types.ValidationRuleResult{Condition: &latestCondition, State: &state}
+}
+
+// errorReasonMessages maps each classified error reason to the message of a failed ValidationRuleResult
+var errorReasonMessages = map[aws_utils.ErrorReason]string{
+	aws_utils.ErrorReasonEntityNotFound:     "Required AWS resource not found",
+	aws_utils.ErrorReasonPluginAccessDenied: "Plugin credentials are not authorized to perform a required AWS action",
+	aws_utils.ErrorReasonThrottled:          "AWS API request was throttled",
+	aws_utils.ErrorReasonTimeout:            "AWS API request timed out",
+	aws_utils.ErrorReasonInvalidSpec:        "AWS rejected a request built from the rule as invalid",
+}
+
+// classifyRuleError adds the reason for a rule's error to its ValidationRuleResult. Classified errors are reported
+// as failed ValidationRuleResults with a message for their reason, rather than as unexpected errors.
+func classifyRuleError(vrr *types.ValidationRuleResult, err error) (*types.ValidationRuleResult, error) {
+	if err == nil || vrr == nil || vrr.Condition == nil {
+		return vrr, err
+	}
+	reason := aws_utils.ClassifyError(err)
+	vrr.Condition.Details = append(vrr.Condition.Details, reasonDetail(reason))
+
+	message, ok := errorReasonMessages[reason]
+	if !ok {
+		return vrr, err
+	}
+	state := vapi.ValidationFailed
+	vrr.State = &state
+	vrr.Condition.Status = corev1.ConditionFalse
+	vrr.Condition.Message = message
+	vrr.Condition.Failures = append(vrr.Condition.Failures, err.Error())
+	return vrr, nil
+}
+
+// clientFailureResult builds a failed ValidationRuleResult for a rule whose AWS client or credentials could not be initialized
+func clientFailureResult(ref ruleRef, err error) *types.ValidationRuleResult {
+	vrr := failedRuleResult(ref, "Failed to initialize AWS client", err.Error())
+	vrr.Condition.Details = append(vrr.Condition.Details, reasonDetail(aws_utils.ClassifyError(err)))
+	return vrr
+}
+
+// reasonDetail formats the machine-readable reason for a rule's error as a ValidationCondition detail
+func reasonDetail(reason aws_utils.ErrorReason) string {
+	return fmt.Sprintf("Reason: %s", reason)
+}
+
+// withAccountID adds the ID of the account that a rule ran against to a ValidationRuleResult's rule name & details
+func withAccountID(vrr *types.ValidationRuleResult, accountID string) {
+	if vrr == nil || vrr.Condition == nil {
+		return
+	}
+	name := strings.TrimPrefix(vrr.Condition.ValidationRule, vapiconstants.ValidationRulePrefix+"-")
+	vrr.Condition.ValidationRule = fmt.Sprintf("%s-%s-%s", vapiconstants.ValidationRulePrefix, accountID, name)
+	vrr.Condition.Details = append([]string{fmt.Sprintf("Account: %s", accountID)}, vrr.Condition.Details...)
+}
@@ -0,0 +1,305 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/aws/smithy-go"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-aws/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-aws/internal/constants"
+	"github.com/spectrocloud-labs/validator-plugin-aws/internal/validators/preflight"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/types"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+func TestRuleRefs(t *testing.T) {
+	cs := []struct {
+		name     string
+		spec     v1alpha1.AwsValidatorSpec
+		expected []ruleRef
+	}{
+		{
+			name:     "no rules",
+			spec:     v1alpha1.AwsValidatorSpec{},
+			expected: []ruleRef{},
+		},
+		{
+			name: "all rule types",
+			spec: v1alpha1.AwsValidatorSpec{
+				IamRoleRules:           []v1alpha1.IamRoleRule{{IamRoleName: "role"}},
+				IamUserRules:           []v1alpha1.IamUserRule{{IamUserName: "user"}},
+				IamGroupRules:          []v1alpha1.IamGroupRule{{IamGroupName: "group"}},
+				IamPolicyRules:         []v1alpha1.IamPolicyRule{{IamPolicyARN: "arn:aws:iam::123456789012:policy/policy"}},
+				IamIrsaRules:           []v1alpha1.IamIrsaRule{{IamRoleName: "irsa"}},
+				IamCustomPolicyRules:   []v1alpha1.IamCustomPolicyRule{{RuleName: "custom"}},
+				IamUserCredentialRules: []v1alpha1.IamUserCredentialRule{{IamUserName: "credentials"}},
+				IamExistenceRules:      []v1alpha1.IamExistenceRule{{Name: "existence"}},
+				IamQuotaRules:          []v1alpha1.IamQuotaRule{{Name: "quota"}},
+				AccountRules:           []v1alpha1.AccountRule{{Name: "account"}},
+				ServiceQuotaRules:      []v1alpha1.ServiceQuotaRule{{Name: "servicequota"}, {Name: "servicequota2"}},
+				TagRules:               []v1alpha1.TagRule{{Name: "tag"}},
+			},
+			expected: []ruleRef{
+				{preflight.RuleName, constants.ValidationTypePreflight},
+				{"role", constants.ValidationTypeIAMRolePolicy},
+				{"user", constants.ValidationTypeIAMUserPolicy},
+				{"group", constants.ValidationTypeIAMGroupPolicy},
+				{"arn:aws:iam::123456789012:policy/policy", constants.ValidationTypeIAMPolicy},
+				{"irsa", constants.ValidationTypeIAMIRSA},
+				{"custom", constants.ValidationTypeIAMCustomPolicy},
+				{"credentials", constants.ValidationTypeIAMUserCredentials},
+				{"existence", constants.ValidationTypeIAMExistence},
+				{"quota", constants.ValidationTypeIAMQuota},
+				{"account", constants.ValidationTypeAccount},
+				{"servicequota", constants.ValidationTypeServiceQuota},
+				{"servicequota2", constants.ValidationTypeServiceQuota},
+				{"tag", constants.ValidationTypeTag},
+			},
+		},
+	}
+	for _, c := range cs {
+		refs := ruleRefs(c.spec)
+		if !reflect.DeepEqual(refs, c.expected) {
+			t.Errorf("%s: expected %v, got %v", c.name, c.expected, refs)
+		}
+		if len(refs) != c.spec.RuleResultCount() {
+			t.Errorf("%s: expected %d refs per RuleResultCount, got %d", c.name, c.spec.RuleResultCount(), len(refs))
+		}
+	}
+}
+
+func TestWithAccountID(t *testing.T) {
+	cs := []struct {
+		name     string
+		vrr      *types.ValidationRuleResult
+		expected *types.ValidationRuleResult
+	}{
+		{
+			name:     "nil result",
+			vrr:      nil,
+			expected: nil,
+		},
+		{
+			name:     "nil condition",
+			vrr:      &types.ValidationRuleResult{},
+			expected: &types.ValidationRuleResult{},
+		},
+		{
+			name: "rule result",
+			vrr: &types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationRule: "validation-role",
+					Details:        []string{"detail"},
+				},
+			},
+			expected: &types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationRule: "validation-222222222222-role",
+					Details:        []string{"Account: 222222222222", "detail"},
+				},
+			},
+		},
+		{
+			name: "rule result without details",
+			vrr: &types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{ValidationRule: "validation-plugin-credentials"},
+			},
+			expected: &types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationRule: "validation-222222222222-plugin-credentials",
+					Details:        []string{"Account: 222222222222"},
+				},
+			},
+		},
+	}
+	for _, c := range cs {
+		withAccountID(c.vrr, "222222222222")
+		if !reflect.DeepEqual(c.vrr, c.expected) {
+			t.Errorf("%s: expected %+v, got %+v", c.name, c.expected, c.vrr)
+		}
+	}
+}
+
+func TestClientFailureResult(t *testing.T) {
+	cs := []struct {
+		name     string
+		err      error
+		expected *types.ValidationRuleResult
+	}{
+		{
+			name: "access denied",
+			err:  fmt.Errorf("failed to retrieve AWS credentials: %w", &smithy.GenericAPIError{Code: "AccessDenied", Message: "not authorized"}),
+			expected: &types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: constants.ValidationTypeIAMRolePolicy,
+					ValidationRule: "validation-role",
+					Message:        "Failed to initialize AWS client",
+					Details:        []string{"Reason: PluginAccessDenied"},
+					Failures:       []string{"failed to retrieve AWS credentials: api error AccessDenied: not authorized"},
+					Status:         corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "unclassified error",
+			err:  ErrSecretNameRequired,
+			expected: &types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: constants.ValidationTypeIAMRolePolicy,
+					ValidationRule: "validation-role",
+					Message:        "Failed to initialize AWS client",
+					Details:        []string{"Reason: Unknown"},
+					Failures:       []string{"auth.secretName is required"},
+					Status:         corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+	}
+	for _, c := range cs {
+		vrr := clientFailureResult(ruleRef{"role", constants.ValidationTypeIAMRolePolicy}, c.err)
+		vrr.Condition.LastValidationTime = metav1.Time{}
+		if !reflect.DeepEqual(vrr, c.expected) {
+			t.Errorf("%s: expected %+v, got %+v", c.name, c.expected.Condition, vrr.Condition)
+		}
+	}
+}
+
+func TestClassifyRuleError(t *testing.T) {
+	newResult := func() *types.ValidationRuleResult {
+		return &types.ValidationRuleResult{
+			Condition: &vapi.ValidationCondition{
+				ValidationType: constants.ValidationTypeIAMRolePolicy,
+				ValidationRule: "validation-role",
+				Message:        "All required aws-iam-role-policy permissions were found",
+				Details:        []string{},
+				Status:         corev1.ConditionTrue,
+			},
+			State: util.Ptr(vapi.ValidationSucceeded),
+		}
+	}
+	notFound := fmt.Errorf("operation error IAM: GetRole: %w", &smithy.GenericAPIError{Code: "NoSuchEntity", Message: "role not found"})
+	unknown := errors.New("unexpected")
+
+	cs := []struct {
+		name        string
+		vrr         *types.ValidationRuleResult
+		err         error
+		expected    *types.ValidationRuleResult
+		expectedErr error
+	}{
+		{
+			name:     "no error",
+			vrr:      newResult(),
+			expected: newResult(),
+		},
+		{
+			name:        "nil result",
+			vrr:         nil,
+			err:         unknown,
+			expected:    nil,
+			expectedErr: unknown,
+		},
+		{
+			name: "classified error",
+			vrr:  newResult(),
+			err:  notFound,
+			expected: &types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: constants.ValidationTypeIAMRolePolicy,
+					ValidationRule: "validation-role",
+					Message:        "Required AWS resource not found",
+					Details:        []string{"Reason: EntityNotFound"},
+					Failures:       []string{notFound.Error()},
+					Status:         corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "unclassified error",
+			vrr:  newResult(),
+			err:  unknown,
+			expected: func() *types.ValidationRuleResult {
+				vrr := newResult()
+				vrr.Condition.Details = []string{"Reason: Unknown"}
+				return vrr
+			}(),
+			expectedErr: unknown,
+		},
+	}
+	for _, c := range cs {
+		vrr, err := classifyRuleError(c.vrr, c.err)
+		if !reflect.DeepEqual(vrr, c.expected) {
+			t.Errorf("%s: expected %+v, got %+v", c.name, c.expected, vrr)
+		}
+		if !errors.Is(err, c.expectedErr) {
+			t.Errorf("%s: expected error %v, got %v", c.name, c.expectedErr, err)
+		}
+	}
+}
+
+func TestExpectedResults(t *testing.T) {
+	rules := v1alpha1.AwsValidatorSpec{
+		TagRules:     []v1alpha1.TagRule{{Name: "tag"}},
+		AccountRules: []v1alpha1.AccountRule{{Name: "account"}},
+	}
+	withAccounts := func(targets *v1alpha1.AccountTargets) v1alpha1.AwsValidatorSpec {
+		spec := rules
+		spec.Accounts = targets
+		return spec
+	}
+	ou := &v1alpha1.AccountTargets{OrganizationalUnitID: "ou-abcd-12345678", RoleName: "OrganizationAccountAccessRole"}
+
+	cs := []struct {
+		name       string
+		spec       v1alpha1.AwsValidatorSpec
+		accountIDs []string
+		expected   int
+	}{
+		{
+			name:     "no account targets",
+			spec:     rules,
+			expected: 3,
+		},
+		{
+			name:     "account IDs, unresolved",
+			spec:     withAccounts(&v1alpha1.AccountTargets{AccountIDs: []string{"111111111111", "222222222222"}}),
+			expected: 6,
+		},
+		{
+			name:       "organizational unit, resolved",
+			spec:       withAccounts(ou),
+			accountIDs: []string{"111111111111", "222222222222", "333333333333"},
+			expected:   9,
+		},
+		{
+			name:     "organizational unit, unresolved",
+			spec:     withAccounts(ou),
+			expected: 3,
+		},
+		{
+			name:       "organizational unit and account IDs, resolved",
+			spec:       withAccounts(&v1alpha1.AccountTargets{AccountIDs: []string{"111111111111"}, OrganizationalUnitID: "ou-abcd-12345678"}),
+			accountIDs: []string{"111111111111", "222222222222"},
+			expected:   6,
+		},
+	}
+	for _, c := range cs {
+		count := expectedResults(c.spec, c.accountIDs)
+		if count != c.expected {
+			t.Errorf("%s: expected %d, got %d", c.name, c.expected, count)
+		}
+		validator := &v1alpha1.AwsValidator{ObjectMeta: metav1.ObjectMeta{Name: "validator"}, Spec: c.spec}
+		if vr := buildValidationResult(validator, count); vr.Spec.ExpectedResults != c.expected {
+			t.Errorf("%s: expected ValidationResult to expect %d results, got %d", c.name, c.expected, vr.Spec.ExpectedResults)
+		}
+	}
+}
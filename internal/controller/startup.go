@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/spectrocloud-labs/validator-plugin-aws/api/v1alpha1"
+	aws_utils "github.com/spectrocloud-labs/validator-plugin-aws/internal/utils/aws"
+	"github.com/spectrocloud-labs/validator-plugin-aws/internal/validators/preflight"
+)
+
+// StartupPreflight runs the plugin credentials preflight once when the plugin starts, so that missing permissions are
+// logged before the first reconcile. Only AwsValidators whose credentials come from the plugin's environment are checked,
+// as configuring the credentials in a secret or shared config modifies the plugin's environment or filesystem.
+// Every AwsValidator is also checked on each reconcile, which reports the preflight as a ValidationResult.
+func (r *AwsValidatorReconciler) StartupPreflight(ctx context.Context) error {
+	validators := &v1alpha1.AwsValidatorList{}
+	if err := r.List(ctx, validators); err != nil {
+		r.Log.V(0).Error(err, "failed to list AwsValidators for startup preflight")
+		return nil
+	}
+
+	for _, validator := range startupPreflightTargets(validators.Items) {
+		l := r.Log.V(0).WithValues("name", validator.Name, "namespace", validator.Namespace)
+
+		caBundle, err := r.caBundle(validator.Spec.Auth.ClientConfig, validator.Namespace)
+		if err != nil {
+			l.Error(err, "failed to resolve CA bundle for startup preflight")
+			continue
+		}
+		awsApi, err := aws_utils.NewAwsApi(r.Log, validator.Spec.Auth, validator.Spec.DefaultRegion, aws_utils.ResolvedAuth{CABundle: caBundle})
+		if err != nil {
+			l.Error(err, "failed to get AWS client for startup preflight")
+			continue
+		}
+		vrr, err := preflight.NewPreflightService(r.Log, awsApi.STS, awsApi.IAM).ReconcilePreflight(validator.Spec, awsApi.CredentialSource(ctx))
+		if err != nil {
+			l.Error(err, "failed to run startup preflight")
+			continue
+		}
+		if len(vrr.Condition.Failures) > 0 {
+			l.Info("Startup preflight: plugin credentials insufficient", "failures", vrr.Condition.Failures)
+			continue
+		}
+		l.Info("Startup preflight: "+vrr.Condition.Message, "details", vrr.Condition.Details)
+	}
+	return nil
+}
+
+// startupPreflightTargets returns the AwsValidators with rules whose credentials come from the plugin's environment,
+// i.e., that use implicit or web identity auth
+func startupPreflightTargets(validators []v1alpha1.AwsValidator) []v1alpha1.AwsValidator {
+	targets := make([]v1alpha1.AwsValidator, 0)
+	for _, validator := range validators {
+		auth := validator.Spec.Auth
+		if validator.Spec.RuleResultCount() == 0 || auth.SharedConfig != nil || (!auth.Implicit && auth.WebIdentity == nil) {
+			continue
+		}
+		targets = append(targets, validator)
+	}
+	return targets
+}
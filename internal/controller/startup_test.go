@@ -0,0 +1,39 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-aws/api/v1alpha1"
+)
+
+func TestStartupPreflightTargets(t *testing.T) {
+	validator := func(name string, auth v1alpha1.AwsAuth, rules bool) v1alpha1.AwsValidator {
+		v := v1alpha1.AwsValidator{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       v1alpha1.AwsValidatorSpec{Auth: auth},
+		}
+		if rules {
+			v.Spec.TagRules = []v1alpha1.TagRule{{Name: "tag"}}
+		}
+		return v
+	}
+	validators := []v1alpha1.AwsValidator{
+		validator("implicit", v1alpha1.AwsAuth{Implicit: true}, true),
+		validator("web-identity", v1alpha1.AwsAuth{WebIdentity: &v1alpha1.AwsWebIdentityAuth{RoleArn: "arn:aws:iam::123456789012:role/plugin"}}, true),
+		validator("no-rules", v1alpha1.AwsAuth{Implicit: true}, false),
+		validator("secret", v1alpha1.AwsAuth{SecretName: "aws-creds"}, true),
+		validator("shared-config", v1alpha1.AwsAuth{SharedConfig: &v1alpha1.AwsSharedConfig{SecretName: "shared-config"}}, true),
+	}
+
+	targets := make([]string, 0)
+	for _, v := range startupPreflightTargets(validators) {
+		targets = append(targets, v.Name)
+	}
+	expected := []string{"implicit", "web-identity"}
+	if !reflect.DeepEqual(targets, expected) {
+		t.Errorf("expected %v, got %v", expected, targets)
+	}
+}
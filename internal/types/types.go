@@ -6,6 +6,8 @@ import "sort"
 type UsageResult struct {
 	Description string
 	MaxUsage    float64
+	// NoData is true if no usage was reported, in which case MaxUsage is assumed to be 0
+	NoData bool
 }
 
 // UsageMap maps categories to their usage
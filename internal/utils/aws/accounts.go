@@ -0,0 +1,72 @@
+package aws
+
+import (
+	"context"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
+	orgtypes "github.com/aws/aws-sdk-go-v2/service/organizations/types"
+
+	"github.com/spectrocloud-labs/validator-plugin-aws/api/v1alpha1"
+	str_utils "github.com/spectrocloud-labs/validator-plugin-aws/internal/utils/strings"
+)
+
+const defaultAccountRoleSessionName = "validator-plugin-aws"
+
+type organizationsApi interface {
+	ListAccountsForParent(ctx context.Context, params *organizations.ListAccountsForParentInput, optFns ...func(*organizations.Options)) (*organizations.ListAccountsForParentOutput, error)
+}
+
+// TargetAccounts returns the sorted IDs of the accounts listed in an AccountTargets and the active accounts in its organizational unit
+func TargetAccounts(ctx context.Context, orgSvc organizationsApi, targets *v1alpha1.AccountTargets) ([]string, error) {
+	accountIDs := append([]string{}, targets.AccountIDs...)
+
+	if targets.OrganizationalUnitID != "" {
+		input := &organizations.ListAccountsForParentInput{ParentId: &targets.OrganizationalUnitID}
+		for {
+			output, err := orgSvc.ListAccountsForParent(ctx, input)
+			if err != nil {
+				return nil, err
+			}
+			for _, a := range output.Accounts {
+				if a.Status == orgtypes.AccountStatusActive && a.Id != nil {
+					accountIDs = append(accountIDs, *a.Id)
+				}
+			}
+			if output.NextToken == nil {
+				break
+			}
+			input.NextToken = output.NextToken
+		}
+	}
+
+	accountIDs = str_utils.DeDupeStrSlice(accountIDs)
+	sort.Strings(accountIDs)
+	return accountIDs, nil
+}
+
+// AccountAuth returns a copy of an AwsAuth that assumes the target role in an account, after any roles in its STS role chain
+func AccountAuth(auth v1alpha1.AwsAuth, targets *v1alpha1.AccountTargets, partition, accountID string) v1alpha1.AwsAuth {
+	role := v1alpha1.AwsSTSRole{
+		RoleArn:         IAMArn(partition, accountID, "role", targets.RoleName),
+		RoleSessionName: targets.RoleSessionName,
+		DurationSeconds: 3600,
+		ExternalId:      targets.ExternalId,
+	}
+	if role.RoleSessionName == "" {
+		role.RoleSessionName = defaultAccountRoleSessionName
+	}
+
+	accountAuth := *auth.DeepCopy()
+	if accountAuth.StsAuth == nil {
+		accountAuth.StsAuth = &v1alpha1.AwsSTSAuth{
+			RoleArn:         role.RoleArn,
+			RoleSessionName: role.RoleSessionName,
+			DurationSeconds: role.DurationSeconds,
+			ExternalId:      role.ExternalId,
+		}
+	} else {
+		accountAuth.StsAuth.RoleChain = append(accountAuth.StsAuth.RoleChain, role)
+	}
+	return accountAuth
+}
@@ -0,0 +1,61 @@
+package aws
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+)
+
+// AWS partitions supported by the plugin
+const (
+	PartitionAWS      string = "aws"
+	PartitionAWSChina string = "aws-cn"
+	PartitionAWSGov   string = "aws-us-gov"
+)
+
+// defaultRegions maps each partition to the region used when no region is configured
+var defaultRegions = map[string]string{
+	PartitionAWS:      "us-east-1",
+	PartitionAWSChina: "cn-north-1",
+	PartitionAWSGov:   "us-gov-west-1",
+}
+
+// PartitionForRegion returns the partition that an AWS region belongs to
+func PartitionForRegion(region string) string {
+	switch {
+	case strings.HasPrefix(region, "cn-"):
+		return PartitionAWSChina
+	case strings.HasPrefix(region, "us-gov-"):
+		return PartitionAWSGov
+	default:
+		return PartitionAWS
+	}
+}
+
+// DefaultRegion returns the default region for a partition, falling back to the commercial partition
+func DefaultRegion(partition string) string {
+	if region, ok := defaultRegions[partition]; ok {
+		return region
+	}
+	return defaultRegions[PartitionAWS]
+}
+
+// ParseARN parses an ARN in any partition
+func ParseARN(s string) (arn.ARN, error) {
+	a, err := arn.Parse(s)
+	if err != nil {
+		return arn.ARN{}, fmt.Errorf("invalid ARN %s: %w", s, err)
+	}
+	return a, nil
+}
+
+// IAMArn builds the ARN of an IAM resource, e.g., a role or user, in a partition & account
+func IAMArn(partition, account, resourceType, name string) string {
+	return arn.ARN{
+		Partition: partition,
+		Service:   "iam",
+		AccountID: account,
+		Resource:  fmt.Sprintf("%s/%s", resourceType, name),
+	}.String()
+}
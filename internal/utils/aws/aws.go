@@ -2,58 +2,188 @@ package aws
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/efs"
 	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancing"
 	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
 	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/servicequotas"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
+	ststypes "github.com/aws/aws-sdk-go-v2/service/sts/types"
 	"github.com/go-logr/logr"
 
 	"github.com/spectrocloud-labs/validator-plugin-aws/api/v1alpha1"
 )
 
 type AwsApi struct {
+	CW    *cloudwatch.Client
 	IAM   *iam.Client
 	EC2   *ec2.Client
 	EFS   *efs.Client
 	ELB   *elasticloadbalancing.Client
 	ELBV2 *elasticloadbalancingv2.Client
+	KMS   *kms.Client
+	ORG   *organizations.Client
+	S3    *s3.Client
 	SQ    *servicequotas.Client
+	SQS   *sqs.Client
+	STS   *sts.Client
+
+	credentials      aws.CredentialsProvider
+	credentialSource string
+}
+
+// ResolvedAuth holds the inputs of an AwsAuth that are resolved from Kubernetes objects
+type ResolvedAuth struct {
+	// The PEM-encoded CA bundle referenced by auth.clientConfig, if any
+	CABundle []byte
+	// Paths of the shared config & credentials files written from auth.sharedConfig, if any
+	SharedConfigFiles      []string
+	SharedCredentialsFiles []string
+	// Static credentials read from a rule's auth override secret, if any
+	Credentials aws.CredentialsProvider
 }
 
-// NewAwsApi creates an AwsApi object that aggregates AWS service clients
-func NewAwsApi(log logr.Logger, auth v1alpha1.AwsAuth, region string) (*AwsApi, error) {
-	cfg, err := config.LoadDefaultConfig(context.TODO(), config.WithDefaultRegion(region))
+// NewAwsApi creates an AwsApi object that aggregates AWS service clients.
+// If no region is specified, the default region of the expected partition (or the commercial partition) is used,
+// so that global services such as IAM and STS resolve to that partition's endpoints.
+func NewAwsApi(log logr.Logger, auth v1alpha1.AwsAuth, region string, resolved ResolvedAuth) (*AwsApi, error) {
+	if region == "" {
+		region = DefaultRegion(auth.ExpectedPartition)
+	}
+	loadOpts := append([]func(*config.LoadOptions) error{config.WithDefaultRegion(region)}, clientConfigLoadOptions(auth.ClientConfig, resolved.CABundle)...)
+	if sc := auth.SharedConfig; sc != nil {
+		profile := sc.Profile
+		if profile == "" {
+			profile = "default"
+		}
+		loadOpts = append(loadOpts,
+			config.WithSharedConfigFiles(resolved.SharedConfigFiles),
+			config.WithSharedCredentialsFiles(resolved.SharedCredentialsFiles),
+			config.WithSharedConfigProfile(profile),
+		)
+	}
+	if resolved.Credentials != nil {
+		loadOpts = append(loadOpts, config.WithCredentialsProvider(resolved.Credentials))
+	}
+	cfg, err := config.LoadDefaultConfig(context.TODO(), loadOpts...)
 	if err != nil {
 		return nil, err
 	}
+	cc := auth.ClientConfig
+	if cc != nil && cc.EndpointURL != "" {
+		cfg.BaseEndpoint = aws.String(cc.EndpointURL)
+	}
+	if auth.WebIdentity != nil {
+		awsWebIdentityConfig(&cfg, auth.WebIdentity, cc)
+	}
 	if auth.StsAuth != nil {
-		awsStsConfig(&cfg, auth.StsAuth)
+		awsStsConfig(&cfg, auth.StsAuth, cc)
 	}
 	return &AwsApi{
-		IAM:   iam.NewFromConfig(cfg),
-		EC2:   ec2.NewFromConfig(cfg),
-		EFS:   efs.NewFromConfig(cfg),
-		ELB:   elasticloadbalancing.NewFromConfig(cfg),
-		ELBV2: elasticloadbalancingv2.NewFromConfig(cfg),
-		SQ:    servicequotas.NewFromConfig(cfg),
+		CW:    cloudwatch.NewFromConfig(cfg, func(o *cloudwatch.Options) { o.BaseEndpoint = endpoint(cc, "cloudwatch", o.BaseEndpoint) }),
+		IAM:   iam.NewFromConfig(cfg, func(o *iam.Options) { o.BaseEndpoint = endpoint(cc, "iam", o.BaseEndpoint) }),
+		EC2:   ec2.NewFromConfig(cfg, func(o *ec2.Options) { o.BaseEndpoint = endpoint(cc, "ec2", o.BaseEndpoint) }),
+		EFS:   efs.NewFromConfig(cfg, func(o *efs.Options) { o.BaseEndpoint = endpoint(cc, "efs", o.BaseEndpoint) }),
+		ELB:   elasticloadbalancing.NewFromConfig(cfg, func(o *elasticloadbalancing.Options) { o.BaseEndpoint = endpoint(cc, "elb", o.BaseEndpoint) }),
+		ELBV2: elasticloadbalancingv2.NewFromConfig(cfg, func(o *elasticloadbalancingv2.Options) { o.BaseEndpoint = endpoint(cc, "elbv2", o.BaseEndpoint) }),
+		KMS:   kms.NewFromConfig(cfg, func(o *kms.Options) { o.BaseEndpoint = endpoint(cc, "kms", o.BaseEndpoint) }),
+		ORG:   organizations.NewFromConfig(cfg, func(o *organizations.Options) { o.BaseEndpoint = endpoint(cc, "organizations", o.BaseEndpoint) }),
+		S3: s3.NewFromConfig(cfg, func(o *s3.Options) {
+			o.BaseEndpoint = endpoint(cc, "s3", o.BaseEndpoint)
+			// AWS emulators generally don't support virtual-hosted-style bucket addressing
+			o.UsePathStyle = cc != nil && cc.EndpointURL != ""
+		}),
+		SQ:  servicequotas.NewFromConfig(cfg, func(o *servicequotas.Options) { o.BaseEndpoint = endpoint(cc, "servicequotas", o.BaseEndpoint) }),
+		SQS: sqs.NewFromConfig(cfg, func(o *sqs.Options) { o.BaseEndpoint = endpoint(cc, "sqs", o.BaseEndpoint) }),
+		STS: sts.NewFromConfig(cfg, func(o *sts.Options) { o.BaseEndpoint = endpoint(cc, "sts", o.BaseEndpoint) }),
+
+		credentials:      cfg.Credentials,
+		credentialSource: describeCredentialSource(auth),
 	}, nil
 }
 
-func awsStsConfig(cfg *aws.Config, auth *v1alpha1.AwsSTSAuth) {
-	creds := stscreds.NewAssumeRoleProvider(sts.NewFromConfig(*cfg), auth.RoleArn, func(o *stscreds.AssumeRoleOptions) {
-		o.Duration = time.Duration(auth.DurationSeconds) * time.Second
-		o.RoleSessionName = auth.RoleSessionName
-		if auth.ExternalId != "" {
-			o.ExternalID = &auth.ExternalId
+// awsStsConfig configures credentials that assume each role in an STS role chain in order,
+// using the credentials of the previous role (or the base credentials, for the first role)
+func awsStsConfig(cfg *aws.Config, auth *v1alpha1.AwsSTSAuth, cc *v1alpha1.AwsClientConfig) {
+	hops := auth.Hops()
+	for i, hop := range hops {
+		hop := hop
+		stsClient := sts.NewFromConfig(*cfg, func(o *sts.Options) { o.BaseEndpoint = endpoint(cc, "sts", o.BaseEndpoint) })
+		creds := stscreds.NewAssumeRoleProvider(stsClient, hop.RoleArn, func(o *stscreds.AssumeRoleOptions) {
+			o.Duration = time.Duration(hop.DurationSeconds) * time.Second
+			o.RoleSessionName = hop.RoleSessionName
+			if hop.ExternalId != "" {
+				o.ExternalID = &hop.ExternalId
+			}
+			if hop.SourceIdentity != "" {
+				o.SourceIdentity = &hop.SourceIdentity
+			}
+			for _, t := range hop.SessionTags {
+				o.Tags = append(o.Tags, ststypes.Tag{Key: aws.String(t.Key), Value: aws.String(t.Value)})
+				if t.Transitive {
+					o.TransitiveTagKeys = append(o.TransitiveTagKeys, t.Key)
+				}
+			}
+			for _, arn := range hop.PolicyArns {
+				o.PolicyARNs = append(o.PolicyARNs, ststypes.PolicyDescriptorType{Arn: aws.String(arn)})
+			}
+		})
+		cfg.Credentials = aws.NewCredentialsCache(&stsHopProvider{
+			provider: creds,
+			roleArn:  hop.RoleArn,
+			hop:      i + 1,
+			hops:     len(hops),
+		})
+	}
+}
+
+// stsHopProvider identifies the hop of an STS role chain in role assumption errors
+type stsHopProvider struct {
+	provider aws.CredentialsProvider
+	roleArn  string
+	hop      int
+	hops     int
+}
+
+// Retrieve assumes the hop's role
+func (p *stsHopProvider) Retrieve(ctx context.Context) (aws.Credentials, error) {
+	creds, err := p.provider.Retrieve(ctx)
+	if err != nil {
+		// an earlier hop's failure surfaces through this hop's AssumeRole call; report the earlier hop
+		var hopErr *StsHopError
+		if errors.As(err, &hopErr) {
+			return aws.Credentials{}, hopErr
 		}
-	})
-	cfg.Credentials = aws.NewCredentialsCache(creds)
+		return aws.Credentials{}, &StsHopError{RoleArn: p.roleArn, Hop: p.hop, Hops: p.hops, Err: err}
+	}
+	return creds, nil
+}
+
+// StsHopError is returned when a role assumption in an STS role chain fails
+type StsHopError struct {
+	RoleArn string
+	Hop     int
+	Hops    int
+	Err     error
+}
+
+func (e *StsHopError) Error() string {
+	return fmt.Sprintf("failed to assume role %s (STS role chain hop %d of %d): %v", e.RoleArn, e.Hop, e.Hops, e.Err)
+}
+
+func (e *StsHopError) Unwrap() error {
+	return e.Err
 }
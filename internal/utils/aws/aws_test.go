@@ -0,0 +1,391 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
+	orgtypes "github.com/aws/aws-sdk-go-v2/service/organizations/types"
+	"github.com/aws/smithy-go"
+	"github.com/go-logr/logr"
+
+	"github.com/spectrocloud-labs/validator-plugin-aws/api/v1alpha1"
+)
+
+type credentialsProviderMock struct {
+	err error
+}
+
+func (m credentialsProviderMock) Retrieve(ctx context.Context) (aws.Credentials, error) {
+	if m.err != nil {
+		return aws.Credentials{}, m.err
+	}
+	return aws.Credentials{AccessKeyID: "AKIAEXAMPLE"}, nil
+}
+
+func TestStsHopProvider(t *testing.T) {
+	hubErr := errors.New("AccessDenied")
+	hub := &stsHopProvider{
+		provider: credentialsProviderMock{err: hubErr},
+		roleArn:  "arn:aws:iam::111111111111:role/hub",
+		hop:      1,
+		hops:     2,
+	}
+	_, err := hub.Retrieve(context.Background())
+	expected := "failed to assume role arn:aws:iam::111111111111:role/hub (STS role chain hop 1 of 2): AccessDenied"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected error %q, got %v", expected, err)
+	}
+	if !errors.Is(err, hubErr) {
+		t.Errorf("expected error to wrap %v", hubErr)
+	}
+
+	// the hub's failure surfaces through the target's AssumeRole call
+	target := &stsHopProvider{
+		provider: credentialsProviderMock{err: fmt.Errorf("failed to retrieve credentials: %w", err)},
+		roleArn:  "arn:aws:iam::222222222222:role/target",
+		hop:      2,
+		hops:     2,
+	}
+	if _, err := target.Retrieve(context.Background()); err == nil || err.Error() != expected {
+		t.Errorf("expected error %q, got %v", expected, err)
+	}
+
+	target.provider = credentialsProviderMock{}
+	if creds, err := target.Retrieve(context.Background()); err != nil || creds.AccessKeyID != "AKIAEXAMPLE" {
+		t.Errorf("expected credentials, got %v (err: %v)", creds, err)
+	}
+}
+
+type organizationsApiMock struct {
+	pages []*organizations.ListAccountsForParentOutput
+}
+
+func (m organizationsApiMock) ListAccountsForParent(ctx context.Context, params *organizations.ListAccountsForParentInput, optFns ...func(*organizations.Options)) (*organizations.ListAccountsForParentOutput, error) {
+	if params.NextToken == nil {
+		return m.pages[0], nil
+	}
+	return m.pages[1], nil
+}
+
+func TestTargetAccounts(t *testing.T) {
+	orgSvc := organizationsApiMock{
+		pages: []*organizations.ListAccountsForParentOutput{
+			{
+				Accounts: []orgtypes.Account{
+					{Id: aws.String("333333333333"), Status: orgtypes.AccountStatusActive},
+					{Id: aws.String("444444444444"), Status: orgtypes.AccountStatusSuspended},
+				},
+				NextToken: aws.String("page2"),
+			},
+			{
+				Accounts: []orgtypes.Account{
+					{Id: aws.String("111111111111"), Status: orgtypes.AccountStatusActive},
+				},
+			},
+		},
+	}
+	targets := &v1alpha1.AccountTargets{
+		AccountIDs:           []string{"222222222222", "111111111111"},
+		OrganizationalUnitID: "ou-abcd-12345678",
+		RoleName:             "OrganizationAccountAccessRole",
+	}
+	accountIDs, err := TargetAccounts(context.Background(), orgSvc, targets)
+	expected := []string{"111111111111", "222222222222", "333333333333"}
+	if err != nil || !reflect.DeepEqual(accountIDs, expected) {
+		t.Errorf("expected %v, got %v (err: %v)", expected, accountIDs, err)
+	}
+}
+
+func TestAccountAuth(t *testing.T) {
+	targets := &v1alpha1.AccountTargets{RoleName: "OrganizationAccountAccessRole", ExternalId: "external-id"}
+
+	auth := AccountAuth(v1alpha1.AwsAuth{Implicit: true}, targets, PartitionAWSGov, "222222222222")
+	expected := &v1alpha1.AwsSTSAuth{
+		RoleArn:         "arn:aws-us-gov:iam::222222222222:role/OrganizationAccountAccessRole",
+		RoleSessionName: "validator-plugin-aws",
+		DurationSeconds: 3600,
+		ExternalId:      "external-id",
+	}
+	if !reflect.DeepEqual(auth.StsAuth, expected) {
+		t.Errorf("expected %+v, got %+v", expected, auth.StsAuth)
+	}
+
+	hub := v1alpha1.AwsAuth{StsAuth: &v1alpha1.AwsSTSAuth{RoleArn: "arn:aws:iam::111111111111:role/hub"}}
+	auth = AccountAuth(hub, targets, PartitionAWS, "222222222222")
+	if hops := auth.StsAuth.Hops(); len(hops) != 2 || hops[1].RoleArn != "arn:aws:iam::222222222222:role/OrganizationAccountAccessRole" {
+		t.Errorf("expected the account role to be chained after the hub role, got %+v", hops)
+	}
+	if len(hub.StsAuth.RoleChain) != 0 {
+		t.Error("expected the original auth to be unmodified")
+	}
+}
+
+func TestDescribeCredentialSource(t *testing.T) {
+	cs := []struct {
+		name     string
+		auth     v1alpha1.AwsAuth
+		expected string
+	}{
+		{
+			name:     "implicit",
+			auth:     v1alpha1.AwsAuth{Implicit: true},
+			expected: "default credential chain",
+		},
+		{
+			name: "web identity with role chain",
+			auth: v1alpha1.AwsAuth{
+				WebIdentity: &v1alpha1.AwsWebIdentityAuth{RoleArn: "arn:aws:iam::111111111111:role/hub"},
+				StsAuth: &v1alpha1.AwsSTSAuth{
+					RoleArn:   "arn:aws:iam::111111111111:role/intermediate",
+					RoleChain: []v1alpha1.AwsSTSRole{{RoleArn: "arn:aws:iam::222222222222:role/target"}},
+				},
+			},
+			expected: "web identity for role arn:aws:iam::111111111111:role/hub with token file /var/run/secrets/validator-plugin-aws/serviceaccount/token, then assume role arn:aws:iam::222222222222:role/target via a chain of 2 roles",
+		},
+		{
+			name:     "shared config",
+			auth:     v1alpha1.AwsAuth{SharedConfig: &v1alpha1.AwsSharedConfig{SecretName: "aws-shared-config"}},
+			expected: "shared config profile default from secret aws-shared-config",
+		},
+	}
+	for _, c := range cs {
+		if source := describeCredentialSource(c.auth); source != c.expected {
+			t.Errorf("%s: expected %q, got %q", c.name, c.expected, source)
+		}
+	}
+}
+
+func TestNewAwsApiSharedConfig(t *testing.T) {
+	for _, k := range []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN", "AWS_PROFILE", "AWS_REGION", "AWS_DEFAULT_REGION"} {
+		t.Setenv(k, "")
+	}
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "config")
+	credentialsFile := filepath.Join(dir, "credentials")
+	if err := os.WriteFile(configFile, []byte("[default]\nregion = us-west-2\n[profile dev]\nregion = eu-west-1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(credentialsFile, []byte("[default]\naws_access_key_id = AKIADEFAULT\naws_secret_access_key = secret\n[dev]\naws_access_key_id = AKIADEV\naws_secret_access_key = secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	resolved := ResolvedAuth{SharedConfigFiles: []string{configFile}, SharedCredentialsFiles: []string{credentialsFile}}
+
+	cs := []struct {
+		name          string
+		profile       string
+		expectedKeyID string
+		expectedErr   bool
+	}{
+		{name: "default profile", profile: "", expectedKeyID: "AKIADEFAULT"},
+		{name: "named profile", profile: "dev", expectedKeyID: "AKIADEV"},
+		{name: "missing profile", profile: "missing", expectedErr: true},
+	}
+	for _, c := range cs {
+		auth := v1alpha1.AwsAuth{SharedConfig: &v1alpha1.AwsSharedConfig{SecretName: "aws-shared-config", Profile: c.profile}}
+		awsApi, err := NewAwsApi(logr.Discard(), auth, "us-east-1", resolved)
+		if c.expectedErr {
+			if err == nil {
+				t.Errorf("%s: expected an error", c.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", c.name, err)
+			continue
+		}
+		creds, err := awsApi.IAM.Options().Credentials.Retrieve(context.Background())
+		if err != nil || creds.AccessKeyID != c.expectedKeyID {
+			t.Errorf("%s: expected access key ID %s, got %s (err: %v)", c.name, c.expectedKeyID, creds.AccessKeyID, err)
+		}
+	}
+}
+
+func TestOverrideAuth(t *testing.T) {
+	auth := v1alpha1.AwsAuth{
+		WebIdentity: &v1alpha1.AwsWebIdentityAuth{RoleArn: "arn:aws:iam::111111111111:role/web-identity"},
+		StsAuth:     &v1alpha1.AwsSTSAuth{RoleArn: "arn:aws:iam::111111111111:role/base"},
+	}
+
+	if overridden := OverrideAuth(auth, nil); !reflect.DeepEqual(overridden, auth) {
+		t.Errorf("expected %+v, got %+v", auth, overridden)
+	}
+
+	stsAuth := &v1alpha1.AwsSTSAuth{RoleArn: "arn:aws:iam::222222222222:role/workload"}
+	overridden := OverrideAuth(auth, &v1alpha1.RuleAuth{StsAuth: stsAuth})
+	if overridden.WebIdentity == nil || !reflect.DeepEqual(overridden.StsAuth, stsAuth) {
+		t.Errorf("expected the STS auth to be replaced and the web identity to be kept, got %+v", overridden)
+	}
+
+	overridden = OverrideAuth(auth, &v1alpha1.RuleAuth{SecretName: "workload-creds"})
+	if overridden.WebIdentity != nil || overridden.SecretName != "workload-creds" || overridden.StsAuth.RoleArn != auth.StsAuth.RoleArn {
+		t.Errorf("expected the base credentials to be replaced and the STS auth to be kept, got %+v", overridden)
+	}
+	if auth.WebIdentity == nil {
+		t.Error("expected the original auth to be unmodified")
+	}
+}
+
+func TestSecretCredentials(t *testing.T) {
+	if _, err := SecretCredentials(map[string][]byte{"AWS_ACCESS_KEY_ID": []byte("AKIAEXAMPLE")}); err == nil {
+		t.Error("expected an error for a secret without AWS_SECRET_ACCESS_KEY")
+	}
+	provider, err := SecretCredentials(map[string][]byte{
+		"AWS_ACCESS_KEY_ID":     []byte("AKIAEXAMPLE"),
+		"AWS_SECRET_ACCESS_KEY": []byte("secret"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if creds, err := provider.Retrieve(context.Background()); err != nil || creds.AccessKeyID != "AKIAEXAMPLE" {
+		t.Errorf("expected credentials, got %v (err: %v)", creds, err)
+	}
+}
+
+func TestRetrieveCredentials(t *testing.T) {
+	if err := (&AwsApi{}).RetrieveCredentials(context.Background()); err != nil {
+		t.Errorf("expected no error for anonymous credentials, got %v", err)
+	}
+	credsErr := errors.New("AccessDenied")
+	if err := (&AwsApi{credentials: credentialsProviderMock{err: credsErr}}).RetrieveCredentials(context.Background()); !errors.Is(err, credsErr) {
+		t.Errorf("expected error %v, got %v", credsErr, err)
+	}
+}
+
+type netTimeoutErr struct{}
+
+func (netTimeoutErr) Error() string   { return "i/o timeout" }
+func (netTimeoutErr) Timeout() bool   { return true }
+func (netTimeoutErr) Temporary() bool { return true }
+
+func TestClassifyError(t *testing.T) {
+	apiErr := func(code string) error {
+		return fmt.Errorf("operation error IAM: GetRole: %w", &smithy.GenericAPIError{Code: code})
+	}
+	cs := []struct {
+		err      error
+		expected ErrorReason
+	}{
+		{nil, ""},
+		{apiErr("NoSuchEntity"), ErrorReasonEntityNotFound},
+		{apiErr("InvalidSubnetID.NotFound"), ErrorReasonEntityNotFound},
+		{apiErr("AccessDenied"), ErrorReasonPluginAccessDenied},
+		{apiErr("UnauthorizedOperation"), ErrorReasonPluginAccessDenied},
+		{&StsHopError{Err: apiErr("AccessDenied")}, ErrorReasonPluginAccessDenied},
+		{apiErr("Throttling"), ErrorReasonThrottled},
+		{apiErr("RequestLimitExceeded"), ErrorReasonThrottled},
+		{apiErr("RequestTimeout"), ErrorReasonTimeout},
+		{fmt.Errorf("request failed: %w", context.DeadlineExceeded), ErrorReasonTimeout},
+		{fmt.Errorf("dial tcp: %w", netTimeoutErr{}), ErrorReasonTimeout},
+		{apiErr("MalformedPolicyDocument"), ErrorReasonInvalidSpec},
+		{apiErr("ServiceFailure"), ErrorReasonUnknown},
+		{errors.New("unexpected"), ErrorReasonUnknown},
+	}
+	for _, c := range cs {
+		if reason := ClassifyError(c.err); reason != c.expected {
+			t.Errorf("%v: expected %s, got %s", c.err, c.expected, reason)
+		}
+	}
+}
+
+func TestEndpoint(t *testing.T) {
+	base := aws.String("https://localhost:4566")
+	cc := &v1alpha1.AwsClientConfig{
+		ServiceEndpoints: map[string]string{
+			"iam": "https://iam.vpce.example.com",
+			"sts": "",
+		},
+	}
+	cs := []struct {
+		name     string
+		cc       *v1alpha1.AwsClientConfig
+		service  string
+		base     *string
+		expected *string
+	}{
+		{"no client config", nil, "iam", base, base},
+		{"overridden", cc, "iam", base, aws.String("https://iam.vpce.example.com")},
+		{"overridden, no base endpoint", cc, "iam", nil, aws.String("https://iam.vpce.example.com")},
+		{"empty override", cc, "sts", base, base},
+		{"not overridden", cc, "ec2", base, base},
+		{"not overridden, no base endpoint", cc, "ec2", nil, nil},
+	}
+	for _, c := range cs {
+		if actual := endpoint(c.cc, c.service, c.base); !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("%s: expected %v, got %v", c.name, aws.ToString(c.expected), aws.ToString(actual))
+		}
+	}
+}
+
+func TestClientConfigLoadOptions(t *testing.T) {
+	load := func(cc *v1alpha1.AwsClientConfig, caBundle []byte) config.LoadOptions {
+		o := config.LoadOptions{}
+		for _, opt := range clientConfigLoadOptions(cc, caBundle) {
+			if err := opt(&o); err != nil {
+				t.Fatalf("failed to apply load option: %v", err)
+			}
+		}
+		return o
+	}
+
+	if o := load(nil, []byte("ca")); o.HTTPClient != nil || o.CustomCABundle != nil {
+		t.Errorf("expected no load options without a client config, got %+v", o)
+	}
+
+	o := load(&v1alpha1.AwsClientConfig{UseFIPSEndpoint: true, UseDualStackEndpoint: true}, nil)
+	if o.UseFIPSEndpoint != aws.FIPSEndpointStateEnabled || o.UseDualStackEndpoint != aws.DualStackEndpointStateEnabled {
+		t.Errorf("expected FIPS & dual-stack endpoints, got %+v", o)
+	}
+	if o.HTTPClient != nil || o.CustomCABundle != nil {
+		t.Errorf("expected no HTTP client or CA bundle, got %+v", o)
+	}
+
+	o = load(&v1alpha1.AwsClientConfig{
+		ProxyURL: "http://proxy.example.com:3128",
+		NoProxy:  "sts.amazonaws.com,.vpce.example.com,10.0.0.0/8",
+	}, []byte("-----BEGIN CERTIFICATE-----"))
+	if o.CustomCABundle == nil {
+		t.Error("expected a custom CA bundle")
+	}
+	client, ok := o.HTTPClient.(*awshttp.BuildableClient)
+	if !ok {
+		t.Fatalf("expected a buildable HTTP client, got %T", o.HTTPClient)
+	}
+	proxy := client.GetTransport().Proxy
+	cs := []struct {
+		url      string
+		expected string
+	}{
+		{"https://iam.amazonaws.com/", "http://proxy.example.com:3128"},
+		{"http://ec2.us-east-1.amazonaws.com/", "http://proxy.example.com:3128"},
+		{"https://sts.amazonaws.com/", ""},
+		{"https://iam.vpce.example.com/", ""},
+		{"https://10.1.2.3/", ""},
+	}
+	for _, c := range cs {
+		req, err := http.NewRequest(http.MethodGet, c.url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		proxyURL, err := proxy(req)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", c.url, err)
+		}
+		actual := ""
+		if proxyURL != nil {
+			actual = proxyURL.String()
+		}
+		if actual != c.expected {
+			t.Errorf("%s: expected proxy %q, got %q", c.url, c.expected, actual)
+		}
+	}
+}
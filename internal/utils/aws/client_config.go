@@ -0,0 +1,56 @@
+package aws
+
+import (
+	"bytes"
+	"net/http"
+	"net/url"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"golang.org/x/net/http/httpproxy"
+
+	"github.com/spectrocloud-labs/validator-plugin-aws/api/v1alpha1"
+)
+
+// clientConfigLoadOptions translates an AwsClientConfig into AWS SDK config load options.
+// caBundle is the PEM-encoded CA bundle referenced by the AwsClientConfig, if any.
+func clientConfigLoadOptions(cc *v1alpha1.AwsClientConfig, caBundle []byte) []func(*config.LoadOptions) error {
+	opts := make([]func(*config.LoadOptions) error, 0)
+	if cc == nil {
+		return opts
+	}
+	if cc.UseFIPSEndpoint {
+		opts = append(opts, config.WithUseFIPSEndpoint(aws.FIPSEndpointStateEnabled))
+	}
+	if cc.UseDualStackEndpoint {
+		opts = append(opts, config.WithUseDualStackEndpoint(aws.DualStackEndpointStateEnabled))
+	}
+	if cc.ProxyURL != "" {
+		proxy := (&httpproxy.Config{
+			HTTPProxy:  cc.ProxyURL,
+			HTTPSProxy: cc.ProxyURL,
+			NoProxy:    cc.NoProxy,
+		}).ProxyFunc()
+		opts = append(opts, config.WithHTTPClient(awshttp.NewBuildableClient().WithTransportOptions(func(tr *http.Transport) {
+			tr.Proxy = func(req *http.Request) (*url.URL, error) {
+				return proxy(req.URL)
+			}
+		})))
+	}
+	if len(caBundle) > 0 {
+		opts = append(opts, config.WithCustomCABundle(bytes.NewReader(caBundle)))
+	}
+	return opts
+}
+
+// endpoint returns the endpoint URL override for a service, or the service's base endpoint if it is not overridden
+func endpoint(cc *v1alpha1.AwsClientConfig, service string, base *string) *string {
+	if cc == nil {
+		return base
+	}
+	if url, ok := cc.ServiceEndpoints[service]; ok && url != "" {
+		return aws.String(url)
+	}
+	return base
+}
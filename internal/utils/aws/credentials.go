@@ -0,0 +1,85 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+
+	"github.com/spectrocloud-labs/validator-plugin-aws/api/v1alpha1"
+)
+
+const (
+	defaultWebIdentityTokenFile       = "/var/run/secrets/validator-plugin-aws/serviceaccount/token"
+	defaultWebIdentityRoleSessionName = "validator-plugin-aws"
+)
+
+// awsWebIdentityConfig configures credentials that assume a role with a projected service account token
+func awsWebIdentityConfig(cfg *aws.Config, auth *v1alpha1.AwsWebIdentityAuth, cc *v1alpha1.AwsClientConfig) {
+	tokenFile := auth.TokenFile
+	if tokenFile == "" {
+		tokenFile = defaultWebIdentityTokenFile
+	}
+	stsClient := sts.NewFromConfig(*cfg, func(o *sts.Options) { o.BaseEndpoint = endpoint(cc, "sts", o.BaseEndpoint) })
+	creds := stscreds.NewWebIdentityRoleProvider(stsClient, auth.RoleArn, stscreds.IdentityTokenFile(tokenFile), func(o *stscreds.WebIdentityRoleOptions) {
+		o.RoleSessionName = auth.RoleSessionName
+		if o.RoleSessionName == "" {
+			o.RoleSessionName = defaultWebIdentityRoleSessionName
+		}
+	})
+	cfg.Credentials = aws.NewCredentialsCache(creds)
+}
+
+// describeCredentialSource describes the configured source of an AwsAuth's credentials
+func describeCredentialSource(auth v1alpha1.AwsAuth) string {
+	var source string
+	switch {
+	case auth.WebIdentity != nil:
+		tokenFile := auth.WebIdentity.TokenFile
+		if tokenFile == "" {
+			tokenFile = defaultWebIdentityTokenFile
+		}
+		source = fmt.Sprintf("web identity for role %s with token file %s", auth.WebIdentity.RoleArn, tokenFile)
+	case auth.SharedConfig != nil:
+		profile := auth.SharedConfig.Profile
+		if profile == "" {
+			profile = "default"
+		}
+		source = fmt.Sprintf("shared config profile %s from secret %s", profile, auth.SharedConfig.SecretName)
+	case !auth.Implicit:
+		source = fmt.Sprintf("environment variables from secret %s", auth.SecretName)
+	default:
+		source = "default credential chain"
+	}
+	if auth.StsAuth != nil {
+		hops := auth.StsAuth.Hops()
+		source = fmt.Sprintf("%s, then assume role %s", source, hops[len(hops)-1].RoleArn)
+		if len(hops) > 1 {
+			source = fmt.Sprintf("%s via a chain of %d roles", source, len(hops))
+		}
+	}
+	return source
+}
+
+// RetrieveCredentials verifies that an AwsApi's credentials can be retrieved, e.g., that each role in an STS role chain can be assumed
+func (a *AwsApi) RetrieveCredentials(ctx context.Context) error {
+	if a.credentials == nil {
+		return nil
+	}
+	_, err := a.credentials.Retrieve(ctx)
+	return err
+}
+
+// CredentialSource describes the source of an AwsApi's credentials, as configured and as resolved by the AWS SDK
+func (a *AwsApi) CredentialSource(ctx context.Context) string {
+	if a.credentials == nil {
+		return a.credentialSource
+	}
+	creds, err := a.credentials.Retrieve(ctx)
+	if err != nil || creds.Source == "" {
+		return a.credentialSource
+	}
+	return fmt.Sprintf("%s (resolved by %s)", a.credentialSource, creds.Source)
+}
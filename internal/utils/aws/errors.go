@@ -0,0 +1,91 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/smithy-go"
+)
+
+// ErrorReason is a machine-readable category of an error returned while evaluating a rule
+type ErrorReason string
+
+const (
+	// ErrorReasonEntityNotFound indicates that an AWS resource referenced by a rule does not exist
+	ErrorReasonEntityNotFound ErrorReason = "EntityNotFound"
+	// ErrorReasonPluginAccessDenied indicates that the plugin's credentials do not allow a required AWS action
+	ErrorReasonPluginAccessDenied ErrorReason = "PluginAccessDenied"
+	// ErrorReasonThrottled indicates that an AWS API request was throttled
+	ErrorReasonThrottled ErrorReason = "Throttled"
+	// ErrorReasonTimeout indicates that an AWS API request timed out
+	ErrorReasonTimeout ErrorReason = "Timeout"
+	// ErrorReasonInvalidSpec indicates that AWS rejected a request built from a rule as invalid
+	ErrorReasonInvalidSpec ErrorReason = "InvalidSpec"
+	// ErrorReasonUnknown indicates an error that could not be classified
+	ErrorReasonUnknown ErrorReason = "Unknown"
+)
+
+var accessDeniedErrorCodes = map[string]struct{}{
+	"AccessDenied":                {},
+	"AccessDeniedException":       {},
+	"AuthFailure":                 {},
+	"AuthorizationError":          {},
+	"ExpiredToken":                {},
+	"ExpiredTokenException":       {},
+	"Forbidden":                   {},
+	"InvalidClientTokenId":        {},
+	"SignatureDoesNotMatch":       {},
+	"UnauthorizedOperation":       {},
+	"UnrecognizedClientException": {},
+}
+
+var invalidSpecErrorCodes = map[string]struct{}{
+	"InvalidInput":                {},
+	"InvalidParameter":            {},
+	"InvalidParameterCombination": {},
+	"InvalidParameterException":   {},
+	"InvalidParameterValue":       {},
+	"MalformedPolicyDocument":     {},
+	"ValidationError":             {},
+	"ValidationException":         {},
+}
+
+// ClassifyError categorizes an error returned while evaluating a rule by its AWS API error code, if any
+func ClassifyError(err error) ErrorReason {
+	if err == nil {
+		return ""
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrorReasonTimeout
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return ErrorReasonTimeout
+	}
+
+	var ae smithy.APIError
+	if !errors.As(err, &ae) {
+		return ErrorReasonUnknown
+	}
+	code := ae.ErrorCode()
+	if _, ok := accessDeniedErrorCodes[code]; ok {
+		return ErrorReasonPluginAccessDenied
+	}
+	if _, ok := retry.DefaultThrottleErrorCodes[code]; ok {
+		return ErrorReasonThrottled
+	}
+	if _, ok := retry.DefaultRetryableErrorCodes[code]; ok {
+		return ErrorReasonTimeout
+	}
+	if _, ok := invalidSpecErrorCodes[code]; ok {
+		return ErrorReasonInvalidSpec
+	}
+	// e.g., NoSuchEntity, NoSuchBucket, InvalidSubnetID.NotFound, ResourceNotFoundException, NonExistentQueue
+	if strings.HasPrefix(code, "NoSuch") || strings.Contains(code, "NotFound") || strings.Contains(code, "NonExistent") {
+		return ErrorReasonEntityNotFound
+	}
+	return ErrorReasonUnknown
+}
@@ -0,0 +1,38 @@
+package aws
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+
+	"github.com/spectrocloud-labs/validator-plugin-aws/api/v1alpha1"
+)
+
+// OverrideAuth returns a copy of an AwsAuth with a rule's auth override applied.
+// A secret replaces the base credentials of the AwsAuth, and STS authentication properties replace its own.
+func OverrideAuth(auth v1alpha1.AwsAuth, override *v1alpha1.RuleAuth) v1alpha1.AwsAuth {
+	overridden := *auth.DeepCopy()
+	if override == nil {
+		return overridden
+	}
+	if override.SecretName != "" {
+		overridden.Implicit = false
+		overridden.SecretName = override.SecretName
+		overridden.SharedConfig = nil
+		overridden.WebIdentity = nil
+	}
+	if override.StsAuth != nil {
+		overridden.StsAuth = override.StsAuth.DeepCopy()
+	}
+	return overridden
+}
+
+// SecretCredentials returns a provider for the static AWS credentials in a Secret's data
+func SecretCredentials(data map[string][]byte) (aws.CredentialsProvider, error) {
+	accessKeyID, secretAccessKey := string(data["AWS_ACCESS_KEY_ID"]), string(data["AWS_SECRET_ACCESS_KEY"])
+	if accessKeyID == "" || secretAccessKey == "" {
+		return nil, fmt.Errorf("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY are required")
+	}
+	return credentials.NewStaticCredentialsProvider(accessKeyID, secretAccessKey, string(data["AWS_SESSION_TOKEN"])), nil
+}
@@ -12,3 +12,29 @@ func DeDupeStrSlice(ss []string) []string {
 	}
 	return l
 }
+
+// MatchWildcard matches a value against a wildcard pattern, where * matches any sequence of characters
+// and ? matches any single character
+func MatchWildcard(pattern, value string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for i := len(value); i >= 0; i-- {
+				if MatchWildcard(pattern[1:], value[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if len(value) == 0 {
+				return false
+			}
+		default:
+			if len(value) == 0 || pattern[0] != value[0] {
+				return false
+			}
+		}
+		pattern, value = pattern[1:], value[1:]
+	}
+	return len(value) == 0
+}
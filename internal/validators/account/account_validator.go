@@ -0,0 +1,136 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamtypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-aws/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-aws/internal/constants"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
+	vapitypes "github.com/spectrocloud-labs/validator/pkg/types"
+)
+
+type accountApi interface {
+	GetAccountPasswordPolicy(ctx context.Context, params *iam.GetAccountPasswordPolicyInput, optFns ...func(*iam.Options)) (*iam.GetAccountPasswordPolicyOutput, error)
+	GetAccountSummary(ctx context.Context, params *iam.GetAccountSummaryInput, optFns ...func(*iam.Options)) (*iam.GetAccountSummaryOutput, error)
+}
+
+type AccountRuleService struct {
+	log        logr.Logger
+	accountSvc accountApi
+}
+
+func NewAccountRuleService(log logr.Logger, accountSvc accountApi) *AccountRuleService {
+	return &AccountRuleService{
+		log:        log,
+		accountSvc: accountSvc,
+	}
+}
+
+// ReconcileAccountRule reconciles an account password policy & root user validation rule from the AWSValidator config
+func (s *AccountRuleService) ReconcileAccountRule(rule v1alpha1.AccountRule) (*vapitypes.ValidationRuleResult, error) {
+
+	// Build the default latest condition for this account rule
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Message = "All account requirements were met"
+	latestCondition.ValidationRule = fmt.Sprintf("%s-%s", vapiconstants.ValidationRulePrefix, rule.Name)
+	latestCondition.ValidationType = constants.ValidationTypeAccount
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	failures := make([]string, 0)
+
+	if rule.PasswordPolicy != nil {
+		policyFailures, err := s.passwordPolicyFailures(rule.PasswordPolicy)
+		if err != nil {
+			return validationResult, err
+		}
+		failures = append(failures, policyFailures...)
+	}
+
+	if rule.RequireRootMFA || rule.DisallowRootAccessKeys {
+		summary, err := s.accountSvc.GetAccountSummary(context.Background(), &iam.GetAccountSummaryInput{})
+		if err != nil {
+			s.log.V(0).Error(err, "failed to get account summary")
+			return validationResult, err
+		}
+		if rule.RequireRootMFA && summary.SummaryMap[string(iamtypes.SummaryKeyTypeAccountMFAEnabled)] != 1 {
+			failures = append(failures, "Root user does not have MFA enabled")
+		}
+		if rule.DisallowRootAccessKeys && summary.SummaryMap[string(iamtypes.SummaryKeyTypeAccountAccessKeysPresent)] != 0 {
+			failures = append(failures, "Root user has one or more access keys")
+		}
+	}
+
+	if len(failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Failures = failures
+		latestCondition.Message = "One or more account requirements was not met"
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+// passwordPolicyFailures compares the account password policy against a set of minimum requirements
+func (s *AccountRuleService) passwordPolicyFailures(req *v1alpha1.PasswordPolicyRequirements) ([]string, error) {
+	output, err := s.accountSvc.GetAccountPasswordPolicy(context.Background(), &iam.GetAccountPasswordPolicyInput{})
+	if err != nil {
+		var nse *iamtypes.NoSuchEntityException
+		if errors.As(err, &nse) {
+			return []string{"Account password policy not found"}, nil
+		}
+		s.log.V(0).Error(err, "failed to get account password policy")
+		return nil, err
+	}
+	policy := output.PasswordPolicy
+	if policy == nil {
+		return []string{"Account password policy not found"}, nil
+	}
+
+	failures := make([]string, 0)
+	if req.MinimumPasswordLength > 0 {
+		if actual := int32Value(policy.MinimumPasswordLength); actual < int32(req.MinimumPasswordLength) {
+			failures = append(failures, fmt.Sprintf("Password policy minimum length %d is less than required minimum %d", actual, req.MinimumPasswordLength))
+		}
+	}
+	if req.PasswordReusePrevention > 0 {
+		if actual := int32Value(policy.PasswordReusePrevention); actual < int32(req.PasswordReusePrevention) {
+			failures = append(failures, fmt.Sprintf("Password policy reuse prevention %d is less than required minimum %d", actual, req.PasswordReusePrevention))
+		}
+	}
+	if req.MaxPasswordAge > 0 {
+		if !policy.ExpirePasswords || policy.MaxPasswordAge == nil {
+			failures = append(failures, fmt.Sprintf("Password policy does not expire passwords; required maximum age is %d days", req.MaxPasswordAge))
+		} else if *policy.MaxPasswordAge > int32(req.MaxPasswordAge) {
+			failures = append(failures, fmt.Sprintf("Password policy maximum age %d days exceeds required maximum %d days", *policy.MaxPasswordAge, req.MaxPasswordAge))
+		}
+	}
+	if req.RequireUppercaseCharacters && !policy.RequireUppercaseCharacters {
+		failures = append(failures, "Password policy does not require uppercase characters")
+	}
+	if req.RequireLowercaseCharacters && !policy.RequireLowercaseCharacters {
+		failures = append(failures, "Password policy does not require lowercase characters")
+	}
+	if req.RequireNumbers && !policy.RequireNumbers {
+		failures = append(failures, "Password policy does not require numbers")
+	}
+	if req.RequireSymbols && !policy.RequireSymbols {
+		failures = append(failures, "Password policy does not require symbols")
+	}
+	return failures, nil
+}
+
+func int32Value(v *int32) int32 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
@@ -0,0 +1,154 @@
+package account
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamtypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-aws/api/v1alpha1"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/types"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+type accountApiMock struct {
+	passwordPolicy *iamtypes.PasswordPolicy
+	summaryMap     map[string]int32
+}
+
+func (m accountApiMock) GetAccountPasswordPolicy(ctx context.Context, params *iam.GetAccountPasswordPolicyInput, optFns ...func(*iam.Options)) (*iam.GetAccountPasswordPolicyOutput, error) {
+	if m.passwordPolicy == nil {
+		return nil, &iamtypes.NoSuchEntityException{}
+	}
+	return &iam.GetAccountPasswordPolicyOutput{PasswordPolicy: m.passwordPolicy}, nil
+}
+
+func (m accountApiMock) GetAccountSummary(ctx context.Context, params *iam.GetAccountSummaryInput, optFns ...func(*iam.Options)) (*iam.GetAccountSummaryOutput, error) {
+	return &iam.GetAccountSummaryOutput{SummaryMap: m.summaryMap}, nil
+}
+
+var accountService = NewAccountRuleService(logr.Logger{}, accountApiMock{
+	passwordPolicy: &iamtypes.PasswordPolicy{
+		ExpirePasswords:            true,
+		MaxPasswordAge:             util.Ptr(int32(90)),
+		MinimumPasswordLength:      util.Ptr(int32(14)),
+		PasswordReusePrevention:    util.Ptr(int32(24)),
+		RequireLowercaseCharacters: true,
+		RequireNumbers:             true,
+		RequireUppercaseCharacters: true,
+	},
+	summaryMap: map[string]int32{
+		"AccountMFAEnabled":        1,
+		"AccountAccessKeysPresent": 1,
+	},
+})
+
+var noPolicyAccountService = NewAccountRuleService(logr.Logger{}, accountApiMock{
+	summaryMap: map[string]int32{
+		"AccountMFAEnabled":        0,
+		"AccountAccessKeysPresent": 0,
+	},
+})
+
+type testCase struct {
+	name           string
+	rule           v1alpha1.AccountRule
+	svc            *AccountRuleService
+	expectedResult types.ValidationRuleResult
+	expectedError  error
+}
+
+func TestAccountValidation(t *testing.T) {
+	cs := []testCase{
+		{
+			name: "Pass (basic)",
+			rule: v1alpha1.AccountRule{
+				Name: "baseline",
+				PasswordPolicy: &v1alpha1.PasswordPolicyRequirements{
+					MinimumPasswordLength:      14,
+					PasswordReusePrevention:    24,
+					MaxPasswordAge:             90,
+					RequireUppercaseCharacters: true,
+					RequireLowercaseCharacters: true,
+					RequireNumbers:             true,
+				},
+				RequireRootMFA: true,
+			},
+			svc: accountService,
+			expectedResult: types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "aws-account",
+					ValidationRule: "validation-baseline",
+					Message:        "All account requirements were met",
+					Details:        []string{},
+					Failures:       nil,
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Fail (weak password policy & root access keys)",
+			rule: v1alpha1.AccountRule{
+				Name: "baseline",
+				PasswordPolicy: &v1alpha1.PasswordPolicyRequirements{
+					MinimumPasswordLength:   16,
+					PasswordReusePrevention: 24,
+					MaxPasswordAge:          60,
+					RequireSymbols:          true,
+				},
+				RequireRootMFA:         true,
+				DisallowRootAccessKeys: true,
+			},
+			svc: accountService,
+			expectedResult: types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "aws-account",
+					ValidationRule: "validation-baseline",
+					Message:        "One or more account requirements was not met",
+					Details:        []string{},
+					Failures: []string{
+						"Password policy minimum length 14 is less than required minimum 16",
+						"Password policy maximum age 90 days exceeds required maximum 60 days",
+						"Password policy does not require symbols",
+						"Root user has one or more access keys",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Fail (no password policy & no root MFA)",
+			rule: v1alpha1.AccountRule{
+				Name:                   "baseline",
+				PasswordPolicy:         &v1alpha1.PasswordPolicyRequirements{MinimumPasswordLength: 14},
+				RequireRootMFA:         true,
+				DisallowRootAccessKeys: true,
+			},
+			svc: noPolicyAccountService,
+			expectedResult: types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "aws-account",
+					ValidationRule: "validation-baseline",
+					Message:        "One or more account requirements was not met",
+					Details:        []string{},
+					Failures: []string{
+						"Account password policy not found",
+						"Root user does not have MFA enabled",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+	}
+	for _, c := range cs {
+		result, err := c.svc.ReconcileAccountRule(c.rule)
+		util.CheckTestCase(t, result, c.expectedResult, err, c.expectedError)
+	}
+}
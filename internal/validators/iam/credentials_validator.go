@@ -0,0 +1,197 @@
+package iam
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamtypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-aws/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-aws/internal/constants"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/types"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+// ReconcileIAMUserCredentialRule reconciles an IAM user credential validation rule from an AWSValidator config
+func (s *IAMRuleService) ReconcileIAMUserCredentialRule(rule v1alpha1.IamUserCredentialRule) (*types.ValidationRuleResult, error) {
+
+	// Build the default ValidationResult for this IAM rule
+	vr := buildValidationResult(rule, constants.ValidationTypeIAMUserCredentials)
+	vr.Condition.Message = "All IAM user credential requirements were met"
+
+	failures := make([]string, 0)
+	now := time.Now()
+
+	// Access keys
+	accessKeys, err := s.iamSvc.ListAccessKeys(context.Background(), &iam.ListAccessKeysInput{
+		UserName: util.Ptr(rule.IamUserName),
+	})
+	if err != nil {
+		s.log.V(0).Error(err, "failed to list access keys", "user", rule.IamUserName)
+		return vr, err
+	}
+
+	activeKeys := 0
+	for _, k := range accessKeys.AccessKeyMetadata {
+		if k.Status != iamtypes.StatusTypeActive || k.AccessKeyId == nil {
+			continue
+		}
+		activeKeys++
+
+		if rule.MaxAccessKeyAgeDays > 0 && k.CreateDate != nil {
+			if age := daysSince(now, *k.CreateDate); age > rule.MaxAccessKeyAgeDays {
+				failures = append(failures, fmt.Sprintf(
+					"Access key %s for IAM user %s is %d days old, exceeding the maximum of %d days",
+					*k.AccessKeyId, rule.IamUserName, age, rule.MaxAccessKeyAgeDays,
+				))
+			}
+		}
+
+		lastUsed, err := s.iamSvc.GetAccessKeyLastUsed(context.Background(), &iam.GetAccessKeyLastUsedInput{
+			AccessKeyId: k.AccessKeyId,
+		})
+		if err != nil {
+			s.log.V(0).Error(err, "failed to get access key last used", "user", rule.IamUserName, "accessKeyId", *k.AccessKeyId)
+			return vr, err
+		}
+		var lastUsedDate *time.Time
+		if lastUsed.AccessKeyLastUsed != nil {
+			lastUsedDate = lastUsed.AccessKeyLastUsed.LastUsedDate
+		}
+		if lastUsedDate == nil {
+			vr.Condition.Details = append(vr.Condition.Details, fmt.Sprintf("Access key %s for IAM user %s has never been used", *k.AccessKeyId, rule.IamUserName))
+		} else {
+			vr.Condition.Details = append(vr.Condition.Details, fmt.Sprintf(
+				"Access key %s for IAM user %s was last used %s", *k.AccessKeyId, rule.IamUserName, lastUsedDate.UTC().Format(time.RFC3339),
+			))
+		}
+
+		if rule.MaxAccessKeyUnusedDays > 0 {
+			// Keys that were never used are measured from their creation date
+			since := lastUsedDate
+			if since == nil {
+				since = k.CreateDate
+			}
+			if since != nil {
+				if unused := daysSince(now, *since); unused > rule.MaxAccessKeyUnusedDays {
+					failures = append(failures, fmt.Sprintf(
+						"Access key %s for IAM user %s has not been used in %d days, exceeding the maximum of %d days",
+						*k.AccessKeyId, rule.IamUserName, unused, rule.MaxAccessKeyUnusedDays,
+					))
+				}
+			}
+		}
+	}
+	if activeKeys > 1 && !rule.AllowMultipleActiveAccessKeys {
+		failures = append(failures, fmt.Sprintf("IAM user %s has %d active access keys", rule.IamUserName, activeKeys))
+	}
+
+	// MFA devices
+	if rule.RequireMFA {
+		mfaDevices, err := s.iamSvc.ListMFADevices(context.Background(), &iam.ListMFADevicesInput{
+			UserName: util.Ptr(rule.IamUserName),
+		})
+		if err != nil {
+			s.log.V(0).Error(err, "failed to list MFA devices", "user", rule.IamUserName)
+			return vr, err
+		}
+		if len(mfaDevices.MFADevices) == 0 {
+			failures = append(failures, fmt.Sprintf("IAM user %s has no MFA device", rule.IamUserName))
+		}
+	}
+
+	// Console password
+	if rule.MaxPasswordAgeDays > 0 {
+		passwords, err := s.credentialReportPasswords()
+		if err != nil {
+			return vr, err
+		}
+		if passwords == nil {
+			vr.Condition.Details = append(vr.Condition.Details, fmt.Sprintf(
+				"IAM credential report is still being generated; console password age for IAM user %s will be checked on the next reconcile", rule.IamUserName,
+			))
+		} else if lastChanged, ok := passwords[rule.IamUserName]; !ok {
+			vr.Condition.Details = append(vr.Condition.Details, fmt.Sprintf(
+				"IAM user %s not found in the IAM credential report; console password age will be checked once the report is regenerated", rule.IamUserName,
+			))
+		} else if lastChanged != nil {
+			if age := daysSince(now, *lastChanged); age > rule.MaxPasswordAgeDays {
+				failures = append(failures, fmt.Sprintf(
+					"Console password for IAM user %s is %d days old, exceeding the maximum of %d days",
+					rule.IamUserName, age, rule.MaxPasswordAgeDays,
+				))
+			}
+		}
+	}
+
+	if len(failures) > 0 {
+		vr.State = util.Ptr(vapi.ValidationFailed)
+		vr.Condition.Failures = failures
+		vr.Condition.Message = "One or more IAM user credential requirements was not met"
+		vr.Condition.Status = corev1.ConditionFalse
+	}
+
+	return vr, nil
+}
+
+// credentialReportPasswords returns when each IAM user's console password was last changed according to the account's
+// IAM credential report, or nil for users without a console password. A nil map is returned if the report isn't ready yet.
+// Unlike the login profile's creation date, the report's password_last_changed column reflects password rotation.
+func (s *IAMRuleService) credentialReportPasswords() (map[string]*time.Time, error) {
+	generated, err := s.iamSvc.GenerateCredentialReport(context.Background(), &iam.GenerateCredentialReportInput{})
+	if err != nil {
+		s.log.V(0).Error(err, "failed to generate IAM credential report")
+		return nil, err
+	}
+	if generated.State != iamtypes.ReportStateTypeComplete {
+		return nil, nil
+	}
+	report, err := s.iamSvc.GetCredentialReport(context.Background(), &iam.GetCredentialReportInput{})
+	if err != nil {
+		s.log.V(0).Error(err, "failed to get IAM credential report")
+		return nil, err
+	}
+
+	records, err := csv.NewReader(bytes.NewReader(report.Content)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse IAM credential report: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, errors.New("IAM credential report is empty")
+	}
+	columns := make(map[string]int)
+	for i, c := range records[0] {
+		columns[c] = i
+	}
+	for _, c := range []string{"user", "password_enabled", "password_last_changed"} {
+		if _, ok := columns[c]; !ok {
+			return nil, fmt.Errorf("IAM credential report missing column %s", c)
+		}
+	}
+
+	passwords := make(map[string]*time.Time)
+	for _, r := range records[1:] {
+		user := r[columns["user"]]
+		passwords[user] = nil
+		if r[columns["password_enabled"]] != "true" {
+			continue
+		}
+		// password_last_changed is N/A or no_information if AWS has no record of a password change
+		if lastChanged, err := time.Parse(time.RFC3339, r[columns["password_last_changed"]]); err == nil {
+			passwords[user] = &lastChanged
+		}
+	}
+	return passwords, nil
+}
+
+// daysSince returns the number of whole days elapsed between t and now
+func daysSince(now, t time.Time) int {
+	return int(now.Sub(t).Hours() / 24)
+}
@@ -0,0 +1,231 @@
+package iam
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamtypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-aws/api/v1alpha1"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/types"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+var (
+	keyCreated  = time.Now().AddDate(0, 0, -120)
+	keyLastUsed = time.Now().AddDate(0, 0, -5)
+	pwdCreated  = time.Now().AddDate(0, 0, -400)
+	pwdChanged  = time.Now().AddDate(0, 0, -10)
+)
+
+const credentialReportHeader = "user,arn,user_creation_time,password_enabled,password_last_used,password_last_changed,password_next_rotation,mfa_active\n"
+
+var credentialsService = NewIAMRuleService(logr.Logger{}, iamApiMock{
+	accessKeys: map[string]*iam.ListAccessKeysOutput{
+		"credUser1": {
+			AccessKeyMetadata: []iamtypes.AccessKeyMetadata{
+				{
+					AccessKeyId: util.Ptr("AKIA1"),
+					CreateDate:  &keyCreated,
+					Status:      iamtypes.StatusTypeActive,
+				},
+				{
+					AccessKeyId: util.Ptr("AKIA2"),
+					CreateDate:  &keyCreated,
+					Status:      iamtypes.StatusTypeInactive,
+				},
+			},
+		},
+		"credUser3": {},
+		"credUser2": {
+			AccessKeyMetadata: []iamtypes.AccessKeyMetadata{
+				{
+					AccessKeyId: util.Ptr("AKIA3"),
+					CreateDate:  &keyCreated,
+					Status:      iamtypes.StatusTypeActive,
+				},
+				{
+					AccessKeyId: util.Ptr("AKIA4"),
+					CreateDate:  &keyCreated,
+					Status:      iamtypes.StatusTypeActive,
+				},
+			},
+		},
+	},
+	accessKeyLastUsed: map[string]*iam.GetAccessKeyLastUsedOutput{
+		"AKIA1": {
+			AccessKeyLastUsed: &iamtypes.AccessKeyLastUsed{LastUsedDate: &keyLastUsed},
+		},
+		"AKIA3": {
+			AccessKeyLastUsed: &iamtypes.AccessKeyLastUsed{LastUsedDate: &keyLastUsed},
+		},
+		"AKIA4": {
+			AccessKeyLastUsed: &iamtypes.AccessKeyLastUsed{},
+		},
+	},
+	mfaDevices: map[string]*iam.ListMFADevicesOutput{
+		"credUser1": {
+			MFADevices: []iamtypes.MFADevice{
+				{SerialNumber: util.Ptr("arn:aws:iam::123456789012:mfa/credUser1")},
+			},
+		},
+		"credUser2": {},
+	},
+	credentialReport: credentialReportHeader +
+		"credUser1,arn:aws:iam::123456789012:user/credUser1," + pwdCreated.UTC().Format(time.RFC3339) + ",false,N/A,N/A,N/A,true\n" +
+		"credUser2,arn:aws:iam::123456789012:user/credUser2," + pwdCreated.UTC().Format(time.RFC3339) + ",true,no_information," + pwdCreated.UTC().Format(time.RFC3339) + ",N/A,false\n" +
+		"credUser3,arn:aws:iam::123456789012:user/credUser3," + pwdCreated.UTC().Format(time.RFC3339) + ",true,no_information," + pwdChanged.UTC().Format(time.RFC3339) + ",N/A,false\n",
+}, nil, nil, nil)
+
+type credentialsTestCase struct {
+	name           string
+	rule           v1alpha1.IamUserCredentialRule
+	expectedResult types.ValidationRuleResult
+	expectedError  error
+}
+
+func TestIAMUserCredentialValidation(t *testing.T) {
+	cs := []credentialsTestCase{
+		{
+			name: "Pass (basic)",
+			rule: v1alpha1.IamUserCredentialRule{
+				IamUserName:            "credUser1",
+				MaxAccessKeyAgeDays:    180,
+				MaxAccessKeyUnusedDays: 30,
+				MaxPasswordAgeDays:     90,
+				RequireMFA:             true,
+			},
+			expectedResult: types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "aws-iam-user-credentials",
+					ValidationRule: "validation-credUser1",
+					Message:        "All IAM user credential requirements were met",
+					Details: []string{
+						"Access key AKIA1 for IAM user credUser1 was last used " + keyLastUsed.UTC().Format(time.RFC3339),
+					},
+					Failures: nil,
+					Status:   corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Fail (stale keys, multiple active keys, no MFA & old password)",
+			rule: v1alpha1.IamUserCredentialRule{
+				IamUserName:            "credUser2",
+				MaxAccessKeyAgeDays:    90,
+				MaxAccessKeyUnusedDays: 30,
+				MaxPasswordAgeDays:     365,
+				RequireMFA:             true,
+			},
+			expectedResult: types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "aws-iam-user-credentials",
+					ValidationRule: "validation-credUser2",
+					Message:        "One or more IAM user credential requirements was not met",
+					Details: []string{
+						"Access key AKIA3 for IAM user credUser2 was last used " + keyLastUsed.UTC().Format(time.RFC3339),
+						"Access key AKIA4 for IAM user credUser2 has never been used",
+					},
+					Failures: []string{
+						"Access key AKIA3 for IAM user credUser2 is 120 days old, exceeding the maximum of 90 days",
+						"Access key AKIA4 for IAM user credUser2 is 120 days old, exceeding the maximum of 90 days",
+						"Access key AKIA4 for IAM user credUser2 has not been used in 120 days, exceeding the maximum of 30 days",
+						"IAM user credUser2 has 2 active access keys",
+						"IAM user credUser2 has no MFA device",
+						"Console password for IAM user credUser2 is 400 days old, exceeding the maximum of 365 days",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Pass (multiple active keys allowed, checks disabled)",
+			rule: v1alpha1.IamUserCredentialRule{
+				IamUserName:                   "credUser2",
+				AllowMultipleActiveAccessKeys: true,
+			},
+			expectedResult: types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "aws-iam-user-credentials",
+					ValidationRule: "validation-credUser2",
+					Message:        "All IAM user credential requirements were met",
+					Details: []string{
+						"Access key AKIA3 for IAM user credUser2 was last used " + keyLastUsed.UTC().Format(time.RFC3339),
+						"Access key AKIA4 for IAM user credUser2 has never been used",
+					},
+					Failures: nil,
+					Status:   corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+	}
+	for _, c := range cs {
+		result, err := credentialsService.ReconcileIAMUserCredentialRule(c.rule)
+		util.CheckTestCase(t, result, c.expectedResult, err, c.expectedError)
+	}
+}
+
+func TestIAMUserPasswordAge(t *testing.T) {
+	pendingService := NewIAMRuleService(logr.Logger{}, iamApiMock{
+		accessKeys: map[string]*iam.ListAccessKeysOutput{"credUser3": {}},
+	}, nil, nil, nil)
+
+	cs := []struct {
+		name           string
+		svc            *IAMRuleService
+		rule           v1alpha1.IamUserCredentialRule
+		expectedResult types.ValidationRuleResult
+	}{
+		{
+			name: "Pass (password rotated after the login profile was created)",
+			svc:  credentialsService,
+			rule: v1alpha1.IamUserCredentialRule{
+				IamUserName:        "credUser3",
+				MaxPasswordAgeDays: 90,
+			},
+			expectedResult: types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "aws-iam-user-credentials",
+					ValidationRule: "validation-credUser3",
+					Message:        "All IAM user credential requirements were met",
+					Details:        []string{},
+					Failures:       nil,
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Pass (credential report not ready)",
+			svc:  pendingService,
+			rule: v1alpha1.IamUserCredentialRule{
+				IamUserName:        "credUser3",
+				MaxPasswordAgeDays: 90,
+			},
+			expectedResult: types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "aws-iam-user-credentials",
+					ValidationRule: "validation-credUser3",
+					Message:        "All IAM user credential requirements were met",
+					Details: []string{
+						"IAM credential report is still being generated; console password age for IAM user credUser3 will be checked on the next reconcile",
+					},
+					Failures: nil,
+					Status:   corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+	}
+	for _, c := range cs {
+		result, err := c.svc.ReconcileIAMUserCredentialRule(c.rule)
+		util.CheckTestCase(t, result, c.expectedResult, err, nil)
+	}
+}
@@ -0,0 +1,219 @@
+package iam
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	awspolicy "github.com/L30Bola/aws-policy"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamtypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-aws/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-aws/internal/constants"
+	str_utils "github.com/spectrocloud-labs/validator-plugin-aws/internal/utils/strings"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/types"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+// ReconcileIAMCustomPolicyRule reconciles a proposed IAM policy validation rule from an AWSValidator config
+func (s *IAMRuleService) ReconcileIAMCustomPolicyRule(rule v1alpha1.IamCustomPolicyRule) (*types.ValidationRuleResult, error) {
+
+	// Build the default ValidationResult for this IAM rule
+	vr := buildValidationResult(rule, constants.ValidationTypeIAMCustomPolicy)
+
+	// Build map of required permissions
+	permissions := buildPermissions(rule)
+
+	// Update the permission map for each proposed policy document
+	policyDocuments := make([]*awspolicy.Policy, 0, len(rule.PolicyDocuments))
+	for i, d := range rule.PolicyDocuments {
+		policyDocument := &awspolicy.Policy{}
+		if err := policyDocument.UnmarshalJSON([]byte(d)); err != nil {
+			s.log.V(0).Error(err, "failed to unmarshal proposed IAM policy", "name", rule.Name(), "index", i)
+			return vr, fmt.Errorf("invalid policy document %d for rule %s: %w", i, rule.Name(), err)
+		}
+		applyPolicy(policyDocument, permissions)
+		policyDocuments = append(policyDocuments, policyDocument)
+	}
+
+	// Compute failures and update the latest condition accordingly
+	computeFailures(rule, permissions, vr)
+	computeExcessPermissions(rule, policyDocuments, vr)
+
+	denials := make([]string, 0)
+	if rule.PermissionsBoundary != "" {
+		boundaryDenials, err := boundaryFailures(rule)
+		if err != nil {
+			s.log.V(0).Error(err, "failed to unmarshal proposed permissions boundary", "name", rule.Name())
+			return vr, err
+		}
+		denials = append(denials, boundaryDenials...)
+	}
+	if rule.Simulate {
+		simulationDenials, err := s.simulateCustomPolicy(rule)
+		if err != nil {
+			s.log.V(0).Error(err, "failed to simulate proposed IAM policies", "name", rule.Name())
+			return vr, err
+		}
+		denials = append(denials, simulationDenials...)
+	}
+	if len(denials) > 0 {
+		vr.State = util.Ptr(vapi.ValidationFailed)
+		vr.Condition.Failures = append(vr.Condition.Failures, str_utils.DeDupeStrSlice(denials)...)
+		vr.Condition.Message = "One or more required IAM permissions was not found, or a condition was not met"
+		vr.Condition.Status = corev1.ConditionFalse
+	}
+
+	return vr, nil
+}
+
+// boundaryFailures determines which required actions a proposed permissions boundary would deny
+func boundaryFailures(rule v1alpha1.IamCustomPolicyRule) ([]string, error) {
+	boundary := &awspolicy.Policy{}
+	if err := boundary.UnmarshalJSON([]byte(rule.PermissionsBoundary)); err != nil {
+		return nil, err
+	}
+	permissions := buildPermissions(rule)
+	applyPolicy(boundary, permissions)
+
+	resources := make([]string, 0, len(permissions))
+	for r := range permissions {
+		resources = append(resources, r)
+	}
+	sort.Strings(resources)
+
+	failures := make([]string, 0)
+	for _, resource := range resources {
+		denied := make([]string, 0)
+		for _, p := range permissions[resource] {
+			for action, allowed := range p.Actions {
+				if !allowed {
+					denied = append(denied, action.String())
+				}
+			}
+		}
+		sort.Strings(denied)
+		for _, action := range denied {
+			failures = append(failures, boundaryDenial(action, resource))
+		}
+	}
+	return failures, nil
+}
+
+// simulateCustomPolicy evaluates the proposed policy documents via SimulateCustomPolicy, without needing an existing
+// principal, and reports required actions that are denied by the permissions boundary. SimulateCustomPolicy never
+// evaluates SCPs, so if a simulation principal is set, the proposed policy documents are additionally evaluated for it
+// via SimulatePrincipalPolicy to report required actions that are denied by an Organization level SCP.
+func (s *IAMRuleService) simulateCustomPolicy(rule v1alpha1.IamCustomPolicyRule) ([]string, error) {
+	var boundaries []string
+	if rule.PermissionsBoundary != "" {
+		boundaries = []string{rule.PermissionsBoundary}
+	}
+
+	failures := make([]string, 0)
+	for _, doc := range rule.Policies {
+		for _, statement := range doc.Statements {
+			if statement.Effect != "Allow" {
+				continue
+			}
+			simulationInput := &iam.SimulateCustomPolicyInput{
+				ActionNames:                        statement.Actions,
+				PermissionsBoundaryPolicyInputList: boundaries,
+				PolicyInputList:                    rule.PolicyDocuments,
+				ResourceArns:                       statement.Resources,
+			}
+
+			var marker *string
+			var simResults []iamtypes.EvaluationResult
+			for {
+				simulationInput.Marker = marker
+
+				simOutput, err := s.iamSvc.SimulateCustomPolicy(context.Background(), simulationInput)
+				if err != nil {
+					return nil, err
+				}
+
+				simResults = append(simResults, simOutput.EvaluationResults...)
+
+				if simOutput.IsTruncated {
+					marker = simOutput.Marker
+					continue
+				}
+				break
+			}
+			failures = append(failures, simulationDenials(simResults, true)...)
+
+			if rule.SimulationPrincipalArn == "" {
+				continue
+			}
+			principalResults, err := s.simulatePrincipalCustomPolicy(rule, statement)
+			if err != nil {
+				return nil, err
+			}
+			// The principal's own permissions boundary, if any, doesn't apply to the proposed principal
+			failures = append(failures, simulationDenials(principalResults, false)...)
+		}
+	}
+	return failures, nil
+}
+
+// simulatePrincipalCustomPolicy evaluates the proposed policy documents for an existing IAM principal via SimulatePrincipalPolicy
+func (s *IAMRuleService) simulatePrincipalCustomPolicy(rule v1alpha1.IamCustomPolicyRule, statement v1alpha1.StatementEntry) ([]iamtypes.EvaluationResult, error) {
+	simulationInput := &iam.SimulatePrincipalPolicyInput{
+		ActionNames:     statement.Actions,
+		PolicyInputList: rule.PolicyDocuments,
+		PolicySourceArn: util.Ptr(rule.SimulationPrincipalArn),
+		ResourceArns:    statement.Resources,
+	}
+
+	var marker *string
+	var simResults []iamtypes.EvaluationResult
+	for {
+		simulationInput.Marker = marker
+
+		simOutput, err := s.iamSvc.SimulatePrincipalPolicy(context.Background(), simulationInput)
+		if err != nil {
+			return nil, err
+		}
+
+		simResults = append(simResults, simOutput.EvaluationResults...)
+
+		if simOutput.IsTruncated {
+			marker = simOutput.Marker
+			continue
+		}
+		break
+	}
+	return simResults, nil
+}
+
+// simulationDenials reports the required actions in a set of IAM policy simulation results that are denied by an
+// Organization level SCP and, optionally, by the permissions boundary
+func simulationDenials(results []iamtypes.EvaluationResult, boundary bool) []string {
+	failures := make([]string, 0)
+	for _, result := range results {
+		if result.EvalDecision == iamtypes.PolicyEvaluationDecisionTypeAllowed || result.EvalActionName == nil {
+			continue
+		}
+		resource := constants.IAMWildcard
+		if result.EvalResourceName != nil {
+			resource = *result.EvalResourceName
+		}
+		if result.OrganizationsDecisionDetail != nil && !result.OrganizationsDecisionDetail.AllowedByOrganizations {
+			failures = append(failures, fmt.Sprintf(
+				"Action %s for resource %s is denied due to an Organization level SCP", *result.EvalActionName, resource,
+			))
+		}
+		if boundary && result.PermissionsBoundaryDecisionDetail != nil && !result.PermissionsBoundaryDecisionDetail.AllowedByPermissionsBoundary {
+			failures = append(failures, boundaryDenial(*result.EvalActionName, resource))
+		}
+	}
+	return failures
+}
+
+func boundaryDenial(action, resource string) string {
+	return fmt.Sprintf("Action %s for resource %s is denied by the permissions boundary", action, resource)
+}
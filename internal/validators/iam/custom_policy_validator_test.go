@@ -0,0 +1,228 @@
+package iam
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamtypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-aws/api/v1alpha1"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/types"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+const (
+	proposedPolicyDocument string = `{
+		"Version": "2012-10-17",
+		"Statement": [
+			{
+				"Effect": "Allow",
+				"Action": ["ec2:DescribeInstances", "s3:GetObject"],
+				"Resource": ["*"]
+			}
+		]
+	}`
+	proposedBoundaryDocument string = `{
+		"Version": "2012-10-17",
+		"Statement": [
+			{
+				"Effect": "Allow",
+				"Action": ["ec2:*"],
+				"Resource": ["*"]
+			}
+		]
+	}`
+)
+
+const simulationPrincipalArn string = "arn:aws:iam::123456789012:role/simulationRole"
+
+var customPolicyService = NewIAMRuleService(logr.Logger{}, iamApiMock{
+	simulateCustomPolicyResult: &iam.SimulateCustomPolicyOutput{
+		EvaluationResults: []iamtypes.EvaluationResult{
+			{
+				EvalActionName:   util.Ptr("ec2:DescribeInstances"),
+				EvalDecision:     iamtypes.PolicyEvaluationDecisionTypeAllowed,
+				EvalResourceName: util.Ptr("*"),
+			},
+			{
+				EvalActionName:                    util.Ptr("s3:GetObject"),
+				EvalDecision:                      iamtypes.PolicyEvaluationDecisionTypeImplicitDeny,
+				EvalResourceName:                  util.Ptr("*"),
+				PermissionsBoundaryDecisionDetail: &iamtypes.PermissionsBoundaryDecisionDetail{AllowedByPermissionsBoundary: false},
+			},
+		},
+	},
+	simulatePrincipalPolicyResult: map[string]*iam.SimulatePrincipalPolicyOutput{
+		simulationPrincipalArn: {
+			EvaluationResults: []iamtypes.EvaluationResult{
+				{
+					EvalActionName:              util.Ptr("ec2:DescribeInstances"),
+					EvalDecision:                iamtypes.PolicyEvaluationDecisionTypeImplicitDeny,
+					EvalResourceName:            util.Ptr("*"),
+					OrganizationsDecisionDetail: &iamtypes.OrganizationsDecisionDetail{AllowedByOrganizations: false},
+				},
+				{
+					EvalActionName:                    util.Ptr("s3:GetObject"),
+					EvalDecision:                      iamtypes.PolicyEvaluationDecisionTypeImplicitDeny,
+					EvalResourceName:                  util.Ptr("*"),
+					OrganizationsDecisionDetail:       &iamtypes.OrganizationsDecisionDetail{AllowedByOrganizations: true},
+					PermissionsBoundaryDecisionDetail: &iamtypes.PermissionsBoundaryDecisionDetail{AllowedByPermissionsBoundary: false},
+				},
+			},
+		},
+	},
+}, nil, nil, nil)
+
+type customPolicyTestCase struct {
+	name           string
+	rule           v1alpha1.IamCustomPolicyRule
+	expectedResult types.ValidationRuleResult
+	expectedError  error
+}
+
+func TestIAMCustomPolicyValidation(t *testing.T) {
+	policies := []v1alpha1.PolicyDocument{
+		{
+			Name:    "iamPolicy",
+			Version: "1",
+			Statements: []v1alpha1.StatementEntry{
+				{
+					Effect:    "Allow",
+					Actions:   []string{"ec2:DescribeInstances", "s3:GetObject"},
+					Resources: []string{"*"},
+				},
+			},
+		},
+	}
+	cs := []customPolicyTestCase{
+		{
+			name: "Pass (basic)",
+			rule: v1alpha1.IamCustomPolicyRule{
+				RuleName:        "proposedRole",
+				PolicyDocuments: []string{proposedPolicyDocument},
+				Policies:        policies,
+			},
+			expectedResult: types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "aws-iam-custom-policy",
+					ValidationRule: "validation-proposedRole",
+					Message:        "All required aws-iam-custom-policy permissions were found",
+					Details:        []string{},
+					Failures:       nil,
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Fail (permissions boundary, simulated without a principal)",
+			rule: v1alpha1.IamCustomPolicyRule{
+				RuleName:            "proposedRole",
+				PolicyDocuments:     []string{proposedPolicyDocument},
+				PermissionsBoundary: proposedBoundaryDocument,
+				Simulate:            true,
+				Policies:            policies,
+			},
+			expectedResult: types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "aws-iam-custom-policy",
+					ValidationRule: "validation-proposedRole",
+					Message:        "One or more required IAM permissions was not found, or a condition was not met",
+					Details:        []string{},
+					Failures: []string{
+						"Action s3:GetObject for resource * is denied by the permissions boundary",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Fail (permissions boundary & SCP)",
+			rule: v1alpha1.IamCustomPolicyRule{
+				RuleName:               "proposedRole",
+				PolicyDocuments:        []string{proposedPolicyDocument},
+				PermissionsBoundary:    proposedBoundaryDocument,
+				Simulate:               true,
+				SimulationPrincipalArn: simulationPrincipalArn,
+				Policies:               policies,
+			},
+			expectedResult: types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "aws-iam-custom-policy",
+					ValidationRule: "validation-proposedRole",
+					Message:        "One or more required IAM permissions was not found, or a condition was not met",
+					Details:        []string{},
+					Failures: []string{
+						"Action s3:GetObject for resource * is denied by the permissions boundary",
+						"Action ec2:DescribeInstances for resource * is denied due to an Organization level SCP",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Fail (unknown action in IAM action catalog)",
+			rule: v1alpha1.IamCustomPolicyRule{
+				RuleName:        "proposedRole",
+				PolicyDocuments: []string{proposedPolicyDocument},
+				Policies: []v1alpha1.PolicyDocument{
+					{
+						Name:    "iamPolicy",
+						Version: "1",
+						Statements: []v1alpha1.StatementEntry{
+							{
+								Effect:    "Allow",
+								Actions:   []string{"ec2:DescribeInstances", "ec2:DescribeSubnet"},
+								Resources: []string{"*"},
+							},
+						},
+					},
+				},
+			},
+			expectedResult: types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "aws-iam-custom-policy",
+					ValidationRule: "validation-proposedRole",
+					Message:        "One or more required IAM permissions was not found, or a condition was not met",
+					Details:        []string{},
+					Failures: []string{
+						"v1alpha1.IamCustomPolicyRule proposedRole missing action(s): [ec2:DescribeSubnet] for resource * from policy iamPolicy",
+						"v1alpha1.IamCustomPolicyRule proposedRole policy iamPolicy has unknown action ec2:DescribeSubnet (IAM action catalog 2024-03-01)",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Fail (invalid policy document)",
+			rule: v1alpha1.IamCustomPolicyRule{
+				RuleName:        "proposedRole",
+				PolicyDocuments: []string{"{"},
+				Policies:        policies,
+			},
+			expectedResult: types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "aws-iam-custom-policy",
+					ValidationRule: "validation-proposedRole",
+					Message:        "All required aws-iam-custom-policy permissions were found",
+					Details:        []string{},
+					Failures:       nil,
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+			expectedError: errors.New("invalid policy document 0 for rule proposedRole: unexpected end of JSON input"),
+		},
+	}
+	for _, c := range cs {
+		result, err := customPolicyService.ReconcileIAMCustomPolicyRule(c.rule)
+		util.CheckTestCase(t, result, c.expectedResult, err, c.expectedError)
+	}
+}
@@ -0,0 +1,84 @@
+package iam
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamtypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"golang.org/x/exp/slices"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-aws/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-aws/internal/constants"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
+	"github.com/spectrocloud-labs/validator/pkg/types"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+// ReconcileIAMExistenceRule reconciles an IAM service-linked role & instance profile validation rule from an AWSValidator config
+func (s *IAMRuleService) ReconcileIAMExistenceRule(rule v1alpha1.IamExistenceRule) (*types.ValidationRuleResult, error) {
+
+	// Build the default latest condition for this IAM rule
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Message = "All required IAM service-linked roles and instance profiles were found"
+	latestCondition.ValidationRule = fmt.Sprintf("%s-%s", vapiconstants.ValidationRulePrefix, rule.Name)
+	latestCondition.ValidationType = constants.ValidationTypeIAMExistence
+	vr := &types.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	failures := make([]string, 0)
+
+	for _, roleName := range rule.ServiceLinkedRoles {
+		role, err := s.iamSvc.GetRole(context.Background(), &iam.GetRoleInput{
+			RoleName: util.Ptr(roleName),
+		})
+		if err != nil {
+			var nse *iamtypes.NoSuchEntityException
+			if !errors.As(err, &nse) {
+				s.log.V(0).Error(err, "failed to get IAM role", "role", roleName)
+				return vr, err
+			}
+			failures = append(failures, fmt.Sprintf("Service-linked role %s not found", roleName))
+			continue
+		}
+		if role.Role.Path == nil || !strings.HasPrefix(*role.Role.Path, constants.ServiceLinkedRolePathPrefix) {
+			failures = append(failures, fmt.Sprintf("IAM role %s is not a service-linked role", roleName))
+		}
+	}
+
+	for _, ip := range rule.InstanceProfiles {
+		profile, err := s.iamSvc.GetInstanceProfile(context.Background(), &iam.GetInstanceProfileInput{
+			InstanceProfileName: util.Ptr(ip.Name),
+		})
+		if err != nil {
+			var nse *iamtypes.NoSuchEntityException
+			if !errors.As(err, &nse) {
+				s.log.V(0).Error(err, "failed to get IAM instance profile", "instanceProfile", ip.Name)
+				return vr, err
+			}
+			failures = append(failures, fmt.Sprintf("Instance profile %s not found", ip.Name))
+			continue
+		}
+		if ip.IamRoleName == "" {
+			continue
+		}
+		if !slices.ContainsFunc(profile.InstanceProfile.Roles, func(r iamtypes.Role) bool {
+			return r.RoleName != nil && *r.RoleName == ip.IamRoleName
+		}) {
+			failures = append(failures, fmt.Sprintf("Instance profile %s does not contain IAM role %s", ip.Name, ip.IamRoleName))
+		}
+	}
+
+	if len(failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Failures = failures
+		latestCondition.Message = "One or more required IAM service-linked roles or instance profiles was not found"
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return vr, nil
+}
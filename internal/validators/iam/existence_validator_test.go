@@ -0,0 +1,117 @@
+package iam
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamtypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-aws/api/v1alpha1"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/types"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+var existenceService = NewIAMRuleService(logr.Logger{}, iamApiMock{
+	role: map[string]*iam.GetRoleOutput{
+		"AWSServiceRoleForElasticLoadBalancing": {
+			Role: &iamtypes.Role{
+				Path:     util.Ptr("/aws-service-role/elasticloadbalancing.amazonaws.com/"),
+				RoleName: util.Ptr("AWSServiceRoleForElasticLoadBalancing"),
+			},
+		},
+		"AWSServiceRoleForAutoScaling": {
+			Role: &iamtypes.Role{
+				Path:     util.Ptr("/"),
+				RoleName: util.Ptr("AWSServiceRoleForAutoScaling"),
+			},
+		},
+	},
+	instanceProfiles: map[string]*iam.GetInstanceProfileOutput{
+		"nodes.cluster-api-provider-aws.sigs.k8s.io": {
+			InstanceProfile: &iamtypes.InstanceProfile{
+				InstanceProfileName: util.Ptr("nodes.cluster-api-provider-aws.sigs.k8s.io"),
+				Roles: []iamtypes.Role{
+					{RoleName: util.Ptr("nodes.cluster-api-provider-aws.sigs.k8s.io")},
+				},
+			},
+		},
+	},
+}, nil, nil, nil)
+
+type existenceTestCase struct {
+	name           string
+	rule           v1alpha1.IamExistenceRule
+	expectedResult types.ValidationRuleResult
+	expectedError  error
+}
+
+func TestIAMExistenceValidation(t *testing.T) {
+	cs := []existenceTestCase{
+		{
+			name: "Pass (basic)",
+			rule: v1alpha1.IamExistenceRule{
+				Name:               "capa",
+				ServiceLinkedRoles: []string{"AWSServiceRoleForElasticLoadBalancing"},
+				InstanceProfiles: []v1alpha1.InstanceProfile{
+					{
+						Name:        "nodes.cluster-api-provider-aws.sigs.k8s.io",
+						IamRoleName: "nodes.cluster-api-provider-aws.sigs.k8s.io",
+					},
+				},
+			},
+			expectedResult: types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "aws-iam-existence",
+					ValidationRule: "validation-capa",
+					Message:        "All required IAM service-linked roles and instance profiles were found",
+					Details:        []string{},
+					Failures:       nil,
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Fail (missing & invalid roles and instance profiles)",
+			rule: v1alpha1.IamExistenceRule{
+				Name: "capa",
+				ServiceLinkedRoles: []string{
+					"AWSServiceRoleForAmazonEKS",
+					"AWSServiceRoleForAutoScaling",
+				},
+				InstanceProfiles: []v1alpha1.InstanceProfile{
+					{
+						Name:        "nodes.cluster-api-provider-aws.sigs.k8s.io",
+						IamRoleName: "control-plane.cluster-api-provider-aws.sigs.k8s.io",
+					},
+					{
+						Name: "control-plane.cluster-api-provider-aws.sigs.k8s.io",
+					},
+				},
+			},
+			expectedResult: types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "aws-iam-existence",
+					ValidationRule: "validation-capa",
+					Message:        "One or more required IAM service-linked roles or instance profiles was not found",
+					Details:        []string{},
+					Failures: []string{
+						"Service-linked role AWSServiceRoleForAmazonEKS not found",
+						"IAM role AWSServiceRoleForAutoScaling is not a service-linked role",
+						"Instance profile nodes.cluster-api-provider-aws.sigs.k8s.io does not contain IAM role control-plane.cluster-api-provider-aws.sigs.k8s.io",
+						"Instance profile control-plane.cluster-api-provider-aws.sigs.k8s.io not found",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+	}
+	for _, c := range cs {
+		result, err := existenceService.ReconcileIAMExistenceRule(c.rule)
+		util.CheckTestCase(t, result, c.expectedResult, err, c.expectedError)
+	}
+}
@@ -2,10 +2,8 @@ package iam
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"net/url"
-	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -19,7 +17,9 @@ import (
 	corev1 "k8s.io/api/core/v1"
 
 	"github.com/spectrocloud-labs/validator-plugin-aws/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-aws/internal/catalog"
 	"github.com/spectrocloud-labs/validator-plugin-aws/internal/constants"
+	aws_utils "github.com/spectrocloud-labs/validator-plugin-aws/internal/utils/aws"
 	str_utils "github.com/spectrocloud-labs/validator-plugin-aws/internal/utils/strings"
 	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
 	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
@@ -27,10 +27,6 @@ import (
 	"github.com/spectrocloud-labs/validator/pkg/util"
 )
 
-const AccountIDFromARNRegex = "arn:[a-z]*:[a-z]*::([?<AccountID>\\d{12}$]*):[0-9A-Za-z]*\\/[0-9A-Za-z]*"
-
-var re = regexp.MustCompile(AccountIDFromARNRegex)
-
 type iamAction struct {
 	Service string
 	Verb    string
@@ -44,6 +40,19 @@ func (a *iamAction) IsAdmin() bool {
 	return a.Service == constants.IAMWildcard && a.Verb == constants.IAMWildcard
 }
 
+func (a *iamAction) IsWildcard() bool {
+	return strings.ContainsAny(a.String(), "*?")
+}
+
+// Covers determines whether an IAM action, which may contain * and ? wildcards, covers another IAM action.
+// IAM action names are case-insensitive.
+func (a *iamAction) Covers(b iamAction) bool {
+	if a.IsAdmin() {
+		return true
+	}
+	return strings.EqualFold(a.Service, b.Service) && stringLike(strings.ToLower(a.Verb), strings.ToLower(b.Verb))
+}
+
 type missing struct {
 	Actions    []string
 	PolicyName string
@@ -58,14 +67,20 @@ type permission struct {
 	Resource    string
 }
 
-type iamRule interface {
+type namedRule interface {
 	Name() string
+}
+
+type iamRule interface {
+	namedRule
 	IAMPolicies() []v1alpha1.PolicyDocument
+	ExcessPermissionsMode() v1alpha1.ExcessPermissionsMode
 }
 
 type iamApi interface {
 	GetPolicy(ctx context.Context, params *iam.GetPolicyInput, optFns ...func(*iam.Options)) (*iam.GetPolicyOutput, error)
 	GetPolicyVersion(ctx context.Context, params *iam.GetPolicyVersionInput, optFns ...func(*iam.Options)) (*iam.GetPolicyVersionOutput, error)
+	ListPolicyVersions(ctx context.Context, params *iam.ListPolicyVersionsInput, optFns ...func(*iam.Options)) (*iam.ListPolicyVersionsOutput, error)
 	ListAttachedGroupPolicies(ctx context.Context, params *iam.ListAttachedGroupPoliciesInput, optFns ...func(*iam.Options)) (*iam.ListAttachedGroupPoliciesOutput, error)
 	ListAttachedRolePolicies(ctx context.Context, params *iam.ListAttachedRolePoliciesInput, optFns ...func(*iam.Options)) (*iam.ListAttachedRolePoliciesOutput, error)
 	ListAttachedUserPolicies(ctx context.Context, params *iam.ListAttachedUserPoliciesInput, optFns ...func(*iam.Options)) (*iam.ListAttachedUserPoliciesOutput, error)
@@ -73,24 +88,46 @@ type iamApi interface {
 	GetGroup(ctx context.Context, params *iam.GetGroupInput, optFns ...func(*iam.Options)) (*iam.GetGroupOutput, error)
 	GetRole(ctx context.Context, params *iam.GetRoleInput, optFns ...func(*iam.Options)) (*iam.GetRoleOutput, error)
 	SimulatePrincipalPolicy(ctx context.Context, params *iam.SimulatePrincipalPolicyInput, optFns ...func(*iam.Options)) (*iam.SimulatePrincipalPolicyOutput, error)
+	SimulateCustomPolicy(ctx context.Context, params *iam.SimulateCustomPolicyInput, optFns ...func(*iam.Options)) (*iam.SimulateCustomPolicyOutput, error)
 	GetContextKeysForPrincipalPolicy(ctx context.Context, params *iam.GetContextKeysForPrincipalPolicyInput, optFns ...func(*iam.Options)) (*iam.GetContextKeysForPrincipalPolicyOutput, error)
+	GetOpenIDConnectProvider(ctx context.Context, params *iam.GetOpenIDConnectProviderInput, optFns ...func(*iam.Options)) (*iam.GetOpenIDConnectProviderOutput, error)
+	GenerateServiceLastAccessedDetails(ctx context.Context, params *iam.GenerateServiceLastAccessedDetailsInput, optFns ...func(*iam.Options)) (*iam.GenerateServiceLastAccessedDetailsOutput, error)
+	GetServiceLastAccessedDetails(ctx context.Context, params *iam.GetServiceLastAccessedDetailsInput, optFns ...func(*iam.Options)) (*iam.GetServiceLastAccessedDetailsOutput, error)
+	ListAccessKeys(ctx context.Context, params *iam.ListAccessKeysInput, optFns ...func(*iam.Options)) (*iam.ListAccessKeysOutput, error)
+	GetAccessKeyLastUsed(ctx context.Context, params *iam.GetAccessKeyLastUsedInput, optFns ...func(*iam.Options)) (*iam.GetAccessKeyLastUsedOutput, error)
+	ListMFADevices(ctx context.Context, params *iam.ListMFADevicesInput, optFns ...func(*iam.Options)) (*iam.ListMFADevicesOutput, error)
+	GenerateCredentialReport(ctx context.Context, params *iam.GenerateCredentialReportInput, optFns ...func(*iam.Options)) (*iam.GenerateCredentialReportOutput, error)
+	GetCredentialReport(ctx context.Context, params *iam.GetCredentialReportInput, optFns ...func(*iam.Options)) (*iam.GetCredentialReportOutput, error)
+	GetInstanceProfile(ctx context.Context, params *iam.GetInstanceProfileInput, optFns ...func(*iam.Options)) (*iam.GetInstanceProfileOutput, error)
+	GetAccountSummary(ctx context.Context, params *iam.GetAccountSummaryInput, optFns ...func(*iam.Options)) (*iam.GetAccountSummaryOutput, error)
+	ListRolePolicies(ctx context.Context, params *iam.ListRolePoliciesInput, optFns ...func(*iam.Options)) (*iam.ListRolePoliciesOutput, error)
+	ListUserPolicies(ctx context.Context, params *iam.ListUserPoliciesInput, optFns ...func(*iam.Options)) (*iam.ListUserPoliciesOutput, error)
+	ListGroupPolicies(ctx context.Context, params *iam.ListGroupPoliciesInput, optFns ...func(*iam.Options)) (*iam.ListGroupPoliciesOutput, error)
+	GetRolePolicy(ctx context.Context, params *iam.GetRolePolicyInput, optFns ...func(*iam.Options)) (*iam.GetRolePolicyOutput, error)
+	GetUserPolicy(ctx context.Context, params *iam.GetUserPolicyInput, optFns ...func(*iam.Options)) (*iam.GetUserPolicyOutput, error)
+	GetGroupPolicy(ctx context.Context, params *iam.GetGroupPolicyInput, optFns ...func(*iam.Options)) (*iam.GetGroupPolicyOutput, error)
 }
 
 type IAMRuleService struct {
 	log    logr.Logger
 	iamSvc iamApi
+	s3Svc  s3Api
+	kmsSvc kmsApi
+	sqsSvc sqsApi
 }
 
-func NewIAMRuleService(log logr.Logger, iamSvc iamApi) *IAMRuleService {
+func NewIAMRuleService(log logr.Logger, iamSvc iamApi, s3Svc s3Api, kmsSvc kmsApi, sqsSvc sqsApi) *IAMRuleService {
 	return &IAMRuleService{
 		log:    log,
 		iamSvc: iamSvc,
+		s3Svc:  s3Svc,
+		kmsSvc: kmsSvc,
+		sqsSvc: sqsSvc,
 	}
 }
 
 // ReconcileIAMRoleRule reconciles an IAM role validation rule from an AWSValidator config
 func (s *IAMRuleService) ReconcileIAMRoleRule(rule iamRule) (*types.ValidationRuleResult, error) {
-	var ctxEntries []iamtypes.ContextEntry
 
 	// Build the default ValidationResult for this IAM rule
 	vr := buildValidationResult(rule, constants.ValidationTypeIAMRolePolicy)
@@ -102,29 +139,40 @@ func (s *IAMRuleService) ReconcileIAMRoleRule(rule iamRule) (*types.ValidationRu
 		return vr, err
 	}
 
+	if err := s.validateRolePolicies(rule, role.Role, vr); err != nil {
+		return vr, err
+	}
+	return vr, nil
+}
+
+// validateRolePolicies compares the policies attached to an IAM role against the policies required by an IAM rule
+func (s *IAMRuleService) validateRolePolicies(rule iamRule, role *iamtypes.Role, vr *types.ValidationRuleResult) error {
+	var ctxEntries []iamtypes.ContextEntry
+
 	policyDocs := rule.IAMPolicies()
 
 	ctxKeys, err := s.iamSvc.GetContextKeysForPrincipalPolicy(context.Background(), &iam.GetContextKeysForPrincipalPolicyInput{
-		PolicySourceArn: util.Ptr(*role.Role.Arn),
+		PolicySourceArn: util.Ptr(*role.Arn),
 	})
 	if err != nil {
-		return vr, err
+		return err
 	}
 	if ctxKeys != nil {
-		ctxEntries, err = getContextEntries(s.log, *role.Role.RoleName, *role.Role.RoleId, *role.Role.Arn, ctxKeys.ContextKeyNames)
+		ctxEntries, err = getContextEntries(s.log, *role.RoleName, *role.RoleId, *role.Arn, ctxKeys.ContextKeyNames)
 		if err != nil {
-			return vr, err
+			return err
 		}
 	}
 
-	scpFailures, err := checkSCP(s.iamSvc, policyDocs, *role.Role.Arn, "role", *role.Role.RoleName, ctxEntries)
+	scpFailures, err := checkSCP(s.iamSvc, policyDocs, *role.Arn, "role", *role.RoleName, ctxEntries)
 	if err != nil {
-		return vr, err
+		return err
 	}
 
 	// SCP related failures found. Exit early
 	if len(scpFailures) > 0 {
-		return getSCPFailedValidationResult(vr, scpFailures), nil
+		getSCPFailedValidationResult(rule, vr, scpFailures)
+		return nil
 	}
 
 	// Retrieve all IAM policies attached to the IAM role
@@ -133,9 +181,9 @@ func (s *IAMRuleService) ReconcileIAMRoleRule(rule iamRule) (*types.ValidationRu
 	})
 	if err != nil {
 		s.log.V(0).Error(err, "failed to list policies for IAM role", "role", rule.Name())
-		return vr, err
+		return err
 	} else if policies == nil {
-		return vr, fmt.Errorf("no policies found for IAM role %s", rule.Name())
+		return fmt.Errorf("no policies found for IAM role %s", rule.Name())
 	}
 
 	// Build map of required permissions
@@ -143,14 +191,24 @@ func (s *IAMRuleService) ReconcileIAMRoleRule(rule iamRule) (*types.ValidationRu
 
 	// Update the permission map for each IAM policy
 	context := []string{"role", rule.Name()}
-	if err := s.processPolicies(policies.AttachedPolicies, permissions, context); err != nil {
-		return vr, err
+	policyDocuments, err := s.processPolicies(policies.AttachedPolicies, permissions, context)
+	if err != nil {
+		return err
+	}
+
+	// Update the permission map for each resource policy, if applicable
+	resourcePolicyFailures, err := s.checkResourcePolicies(rule, *role.Arn, permissions, vr)
+	if err != nil {
+		return err
 	}
 
 	// Compute failures and update the latest condition accordingly
 	computeFailures(rule, permissions, vr)
+	applyResourcePolicyFailures(vr, resourcePolicyFailures)
+	computeExcessPermissions(rule, policyDocuments, vr)
 
-	return vr, nil
+	// Report unused services, if applicable
+	return s.checkLastAccessed(rule, *role.Arn, vr)
 }
 
 // ReconcileIAMUserRule reconciles an IAM user validation rule from an AWSValidator config
@@ -189,7 +247,7 @@ func (s *IAMRuleService) ReconcileIAMUserRule(rule iamRule) (*types.ValidationRu
 
 	// SCP related failures found. Exit early
 	if len(scpFailures) > 0 {
-		return getSCPFailedValidationResult(vr, scpFailures), nil
+		return getSCPFailedValidationResult(rule, vr, scpFailures), nil
 	}
 
 	// Retrieve all IAM policies attached to the IAM user
@@ -208,25 +266,32 @@ func (s *IAMRuleService) ReconcileIAMUserRule(rule iamRule) (*types.ValidationRu
 
 	// Update the permission map for each IAM policy
 	context := []string{"user", rule.Name()}
-	if err := s.processPolicies(policies.AttachedPolicies, permissions, context); err != nil {
+	policyDocuments, err := s.processPolicies(policies.AttachedPolicies, permissions, context)
+	if err != nil {
+		return vr, err
+	}
+
+	// Update the permission map for each resource policy, if applicable
+	resourcePolicyFailures, err := s.checkResourcePolicies(rule, *user.User.Arn, permissions, vr)
+	if err != nil {
 		return vr, err
 	}
 
 	// Compute failures and update the latest condition accordingly
 	computeFailures(rule, permissions, vr)
+	applyResourcePolicyFailures(vr, resourcePolicyFailures)
+	computeExcessPermissions(rule, policyDocuments, vr)
 
 	return vr, nil
 }
 
-func getAccountIDFromARN(arn string) (string, error) {
-	matches := re.FindStringSubmatch(arn)
-
-	if matches == nil || len(matches) < 2 {
-		return "", errors.New("error getting org ID from Account ARN")
+// getAccountIDFromARN returns the account ID of an ARN in any partition
+func getAccountIDFromARN(entityARN string) (string, error) {
+	a, err := aws_utils.ParseARN(entityARN)
+	if err != nil {
+		return "", err
 	}
-
-	//return first captured group - Account ID
-	return matches[1], nil
+	return a.AccountID, nil
 }
 
 func getContextEntries(log logr.Logger, entityName, entityID, entityARN string, contextKeys []string) ([]iamtypes.ContextEntry, error) {
@@ -310,7 +375,7 @@ func (s *IAMRuleService) ReconcileIAMGroupRule(rule iamRule) (*types.ValidationR
 
 	// SCP related failures found. Exit early
 	if len(scpFailures) > 0 {
-		return getSCPFailedValidationResult(vr, scpFailures), nil
+		return getSCPFailedValidationResult(rule, vr, scpFailures), nil
 	}
 
 	// Retrieve all IAM policies attached to the IAM user
@@ -329,19 +394,21 @@ func (s *IAMRuleService) ReconcileIAMGroupRule(rule iamRule) (*types.ValidationR
 
 	// Update the permission map for each IAM policy
 	context := []string{"group", rule.Name()}
-	if err := s.processPolicies(policies.AttachedPolicies, permissions, context); err != nil {
+	policyDocuments, err := s.processPolicies(policies.AttachedPolicies, permissions, context)
+	if err != nil {
 		return vr, err
 	}
 
 	// Compute failures and update the latest condition accordingly
 	computeFailures(rule, permissions, vr)
+	computeExcessPermissions(rule, policyDocuments, vr)
 
 	return vr, nil
 }
 
-func getSCPFailedValidationResult(vr *types.ValidationRuleResult, failures []string) *types.ValidationRuleResult {
+func getSCPFailedValidationResult(rule iamRule, vr *types.ValidationRuleResult, failures []string) *types.ValidationRuleResult {
 	vr.State = util.Ptr(vapi.ValidationFailed)
-	vr.Condition.Failures = failures
+	vr.Condition.Failures = append(lintRule(rule), failures...)
 	vr.Condition.Message = "One or more required SCP permissions was not found, or a condition was not met"
 	vr.Condition.Status = corev1.ConditionFalse
 
@@ -354,6 +421,11 @@ func (s *IAMRuleService) ReconcileIAMPolicyRule(rule iamRule) (*types.Validation
 	// Build the default ValidationResult for this IAM rule
 	vr := buildValidationResult(rule, constants.ValidationTypeIAMPolicy)
 
+	// Evaluate a specific, non-default version of the IAM policy, if requested
+	if pr, ok := rule.(policyVersionRule); ok && pr.TargetPolicyVersion() != "" {
+		return s.reconcilePolicyVersion(rule, pr.TargetPolicyVersion(), vr)
+	}
+
 	// Build map of required permissions
 	permissions := buildPermissions(rule)
 
@@ -367,6 +439,7 @@ func (s *IAMRuleService) ReconcileIAMPolicyRule(rule iamRule) (*types.Validation
 
 	// Compute failures and update the latest condition accordingly
 	computeFailures(rule, permissions, vr)
+	computeExcessPermissions(rule, []*awspolicy.Policy{policyDocument}, vr)
 
 	return vr, nil
 }
@@ -414,18 +487,21 @@ func checkSCP(iamSvc iamApi, policyDocs []v1alpha1.PolicyDocument, policySourceA
 	return scpFailures, nil
 }
 
-// processPolicies updates an IAM permission map for each IAM policy in an array of IAM policies attached to a IAM user / group / role
-func (s *IAMRuleService) processPolicies(policies []iamtypes.AttachedPolicy, permissions map[string][]*permission, context []string) error {
+// processPolicies updates an IAM permission map for each IAM policy in an array of IAM policies attached to a IAM user / group / role.
+// The parsed policy documents are returned for further evaluation.
+func (s *IAMRuleService) processPolicies(policies []iamtypes.AttachedPolicy, permissions map[string][]*permission, context []string) ([]*awspolicy.Policy, error) {
+	policyDocuments := make([]*awspolicy.Policy, 0, len(policies))
 	for _, p := range policies {
 		policyDocument, err := s.getPolicyDocument(p.PolicyArn, context)
 		if err != nil {
-			return err
+			return nil, err
 		} else if policyDocument == nil {
 			continue
 		}
 		applyPolicy(policyDocument, permissions)
+		policyDocuments = append(policyDocuments, policyDocument)
 	}
-	return nil
+	return policyDocuments, nil
 }
 
 // getPolicyDocument generates an awspolicy.Policy, given an AWS IAM policy ARN
@@ -438,23 +514,28 @@ func (s *IAMRuleService) getPolicyDocument(policyArn *string, ctx []string) (*aw
 		s.log.V(0).Error(err, "failed to get IAM policy", ctx[0], ctx[1], "policyArn", policyArn)
 		return nil, err
 	}
+	return s.getPolicyVersionDocument(policyArn, policyOutput.Policy.DefaultVersionId, ctx)
+}
+
+// getPolicyVersionDocument fetches and parses the policy document for a specific version of an IAM policy
+func (s *IAMRuleService) getPolicyVersionDocument(policyArn, versionId *string, ctx []string) (*awspolicy.Policy, error) {
 	policyVersionOutput, err := s.iamSvc.GetPolicyVersion(context.Background(), &iam.GetPolicyVersionInput{
 		PolicyArn: policyArn,
-		VersionId: policyOutput.Policy.DefaultVersionId,
+		VersionId: versionId,
 	})
 	if err != nil {
-		s.log.V(0).Error(err, "failed to get IAM policy version", "policyArn", policyArn, "versionId", policyOutput.Policy.DefaultVersionId)
+		s.log.V(0).Error(err, "failed to get IAM policy version", "policyArn", policyArn, "versionId", versionId)
 		return nil, err
 	}
 
 	// Parse the policy document
 	if policyVersionOutput.PolicyVersion.Document == nil {
-		s.log.V(0).Info("Skipping IAM policy with empty permissions", "policyArn", policyArn, "versionId", policyOutput.Policy.DefaultVersionId)
+		s.log.V(0).Info("Skipping IAM policy with empty permissions", "policyArn", policyArn, "versionId", versionId)
 		return nil, nil
 	}
 	policyUnescaped, err := url.QueryUnescape(*policyVersionOutput.PolicyVersion.Document)
 	if err != nil {
-		s.log.V(0).Error(err, "failed to decode IAM policy document", "policyArn", policyArn, "versionId", policyOutput.Policy.DefaultVersionId)
+		s.log.V(0).Error(err, "failed to decode IAM policy document", "policyArn", policyArn, "versionId", versionId)
 		return nil, err
 	}
 	policyDocument := &awspolicy.Policy{}
@@ -466,7 +547,7 @@ func (s *IAMRuleService) getPolicyDocument(policyArn *string, ctx []string) (*aw
 }
 
 // buildValidationResult builds a default ValidationResult for a given validation type
-func buildValidationResult(rule iamRule, validationType string) *types.ValidationRuleResult {
+func buildValidationResult(rule namedRule, validationType string) *types.ValidationRuleResult {
 	state := vapi.ValidationSucceeded
 	latestCondition := vapi.DefaultValidationCondition()
 	latestCondition.Message = fmt.Sprintf("All required %s permissions were found", validationType)
@@ -497,7 +578,9 @@ func buildPermissions(rule iamRule) map[string][]*permission {
 					resourcePerm.Condition = s.Condition
 				}
 				for _, action := range s.Actions {
-					resourcePerm.Actions[toIAMAction(action)] = false
+					for _, a := range expandAction(action) {
+						resourcePerm.Actions[toIAMAction(a)] = false
+					}
 				}
 				permissions[r] = append(permissions[r], resourcePerm)
 			}
@@ -506,6 +589,40 @@ func buildPermissions(rule iamRule) map[string][]*permission {
 	return permissions
 }
 
+// expandAction expands a required IAM action containing partial wildcards, e.g., ec2:Describe*, into the matching actions
+// from the IAM action catalog. The action is returned as-is if it contains no wildcards, if it's a wildcard for all actions
+// or all of a service's actions, or if the catalog has no matching actions.
+func expandAction(action string) []string {
+	if !strings.ContainsAny(action, "*?") || action == constants.IAMWildcard || toIAMAction(action).Verb == constants.IAMWildcard {
+		return []string{action}
+	}
+	if expanded := catalog.Default().Expand(action); len(expanded) > 0 {
+		return expanded
+	}
+	return []string{action}
+}
+
+// lintRule validates the actions and resources in an IAM rule's policy documents against the IAM action catalog
+func lintRule(rule iamRule) []string {
+	c := catalog.Default()
+	failures := make([]string, 0)
+	for _, p := range rule.IAMPolicies() {
+		for _, s := range p.Statements {
+			for _, a := range s.Actions {
+				if msg := c.LintAction(a); msg != "" {
+					failures = append(failures, fmt.Sprintf("%T %s policy %s has %s (IAM action catalog %s)", rule, rule.Name(), p.Name, msg, c.Version))
+				}
+			}
+			for _, r := range s.Resources {
+				if msg := c.LintResource(r); msg != "" {
+					failures = append(failures, fmt.Sprintf("%T %s policy %s has %s (IAM action catalog %s)", rule, rule.Name(), p.Name, msg, c.Version))
+				}
+			}
+		}
+	}
+	return str_utils.DeDupeStrSlice(failures)
+}
+
 func toIAMAction(action string) iamAction {
 	if action == constants.IAMWildcard {
 		return iamAction{
@@ -514,6 +631,9 @@ func toIAMAction(action string) iamAction {
 		}
 	}
 	actionParts := strings.Split(action, ":")
+	if len(actionParts) < 2 {
+		return iamAction{Service: action}
+	}
 	return iamAction{
 		Service: actionParts[0],
 		Verb:    actionParts[1],
@@ -572,44 +692,10 @@ func updatePermissions(s awspolicy.Statement, permissions []*permission, actionA
 		}
 		for _, action := range s.Action {
 			iamAction := toIAMAction(action)
-			updatePermissionAction(permission, iamAction, actionAllowed)
-
-			if iamAction.IsAdmin() {
-				// update all permissions & exit early
-				for a := range permission.Actions {
+			for a := range permission.Actions {
+				if iamAction.Covers(a) {
 					updatePermissionAction(permission, a, actionAllowed)
 				}
-				return
-			} else if iamAction.Verb == constants.IAMWildcard {
-				// update all permissions for the relevant service
-				for a := range permission.Actions {
-					if a.Service == iamAction.Service {
-						updatePermissionAction(permission, a, actionAllowed)
-					}
-				}
-			} else if strings.HasPrefix(iamAction.Verb, constants.IAMWildcard) {
-				if strings.HasSuffix(iamAction.Verb, constants.IAMWildcard) {
-					// handle actions with a wildcard prefix & suffix, e.g. iam:*Group*
-					for a := range permission.Actions {
-						if a.Service == iamAction.Service && strings.Contains(a.Verb, iamAction.Verb[1:len(iamAction.Verb)-1]) {
-							updatePermissionAction(permission, a, actionAllowed)
-						}
-					}
-				} else {
-					// handle actions with a wildcard prefix, e.g. s3:*Buckets
-					for a := range permission.Actions {
-						if a.Service == iamAction.Service && strings.HasSuffix(a.Verb, iamAction.Verb[1:]) {
-							updatePermissionAction(permission, a, actionAllowed)
-						}
-					}
-				}
-			} else if strings.HasSuffix(iamAction.Verb, constants.IAMWildcard) {
-				// handle actions with a wildcard suffix, e.g. s3:List*
-				for a := range permission.Actions {
-					if a.Service == iamAction.Service && strings.HasPrefix(a.Verb, iamAction.Verb[:len(iamAction.Verb)-1]) {
-						updatePermissionAction(permission, a, actionAllowed)
-					}
-				}
 			}
 		}
 	}
@@ -623,7 +709,7 @@ func updatePermissionAction(permission *permission, a iamAction, actionAllowed b
 
 // computeFailures derives IAM rule failures from an IAM permissions map once it has been fully updated
 func computeFailures(rule iamRule, permissions map[string][]*permission, vr *types.ValidationRuleResult) {
-	failures := make([]string, 0)
+	failures := lintRule(rule)
 	missingActions := make(map[string]*missing)
 
 	for resource, resourcePermissions := range permissions {
@@ -682,3 +768,90 @@ func computeFailures(rule iamRule, permissions map[string][]*permission, vr *typ
 		vr.Condition.Status = corev1.ConditionFalse
 	}
 }
+
+// computeExcessPermissions reports the actions granted by a set of IAM policies that are not required by an IAM rule.
+// Wildcard grants are summarized by service. Excess permissions are reported as details or failures, per the rule's mode.
+func computeExcessPermissions(rule iamRule, policyDocuments []*awspolicy.Policy, vr *types.ValidationRuleResult) {
+	mode := rule.ExcessPermissionsMode()
+	if mode == "" {
+		return
+	}
+
+	required := make([]iamAction, 0)
+	for _, p := range rule.IAMPolicies() {
+		for _, s := range p.Statements {
+			if s.Effect != "Allow" {
+				continue
+			}
+			for _, a := range s.Actions {
+				required = append(required, toIAMAction(a))
+			}
+		}
+	}
+
+	excessActions := make(map[string][]string)
+	wildcardGrants := make(map[string][]string)
+	notActionGrants := make([]string, 0)
+	for _, policyDocument := range policyDocuments {
+		if policyDocument == nil {
+			continue
+		}
+		for _, s := range policyDocument.Statements {
+			if s.Effect != "Allow" {
+				continue
+			}
+			if len(s.NotAction) > 0 {
+				// an Allow statement with a NotAction element grants every action it doesn't list
+				notActionGrants = append(notActionGrants, s.NotAction...)
+			}
+			for _, action := range s.Action {
+				granted := toIAMAction(action)
+				if granted.IsWildcard() {
+					if !slices.ContainsFunc(required, func(r iamAction) bool { return r.Covers(granted) }) {
+						wildcardGrants[granted.Service] = append(wildcardGrants[granted.Service], granted.String())
+					}
+					continue
+				}
+				if !slices.ContainsFunc(required, func(r iamAction) bool { return r.Covers(granted) }) {
+					excessActions[granted.Service] = append(excessActions[granted.Service], granted.String())
+				}
+			}
+		}
+	}
+
+	excess := make([]string, 0)
+	for service, grants := range wildcardGrants {
+		grants = str_utils.DeDupeStrSlice(grants)
+		slices.Sort(grants)
+		if service == constants.IAMWildcard {
+			excess = append(excess, fmt.Sprintf("%T %s granted wildcard action(s) for all services: %s", rule, rule.Name(), grants))
+			continue
+		}
+		excess = append(excess, fmt.Sprintf("%T %s granted wildcard action(s) for service %s: %s", rule, rule.Name(), service, grants))
+	}
+	if len(notActionGrants) > 0 {
+		notActionGrants = str_utils.DeDupeStrSlice(notActionGrants)
+		slices.Sort(notActionGrants)
+		excess = append(excess, fmt.Sprintf("%T %s granted all actions except %s via a NotAction statement", rule, rule.Name(), notActionGrants))
+	}
+	for service, actions := range excessActions {
+		actions = str_utils.DeDupeStrSlice(actions)
+		slices.Sort(actions)
+		excess = append(excess, fmt.Sprintf("%T %s granted excess action(s) for service %s: %s", rule, rule.Name(), service, actions))
+	}
+	if len(excess) == 0 {
+		return
+	}
+	slices.Sort(excess)
+
+	if mode == v1alpha1.ExcessPermissionsWarn {
+		vr.Condition.Details = append(vr.Condition.Details, excess...)
+		return
+	}
+	if len(vr.Condition.Failures) == 0 {
+		vr.Condition.Message = "One or more granted IAM permissions was not required"
+	}
+	vr.State = util.Ptr(vapi.ValidationFailed)
+	vr.Condition.Failures = append(vr.Condition.Failures, excess...)
+	vr.Condition.Status = corev1.ConditionFalse
+}
@@ -6,9 +6,11 @@ import (
 	"net/url"
 	"testing"
 
+	awspolicy "github.com/L30Bola/aws-policy"
 	"github.com/aws/aws-sdk-go-v2/service/iam"
 	iamtypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
 	"github.com/go-logr/logr"
+	"golang.org/x/exp/slices"
 	corev1 "k8s.io/api/core/v1"
 
 	"github.com/spectrocloud-labs/validator-plugin-aws/api/v1alpha1"
@@ -23,11 +25,23 @@ type iamApiMock struct {
 	attachedUserPolicies          map[string]*iam.ListAttachedUserPoliciesOutput
 	policyArns                    map[string]*iam.GetPolicyOutput
 	policyVersions                map[string]*iam.GetPolicyVersionOutput
+	policyVersionsById            map[string]map[string]*iam.GetPolicyVersionOutput
+	listPolicyVersions            map[string]*iam.ListPolicyVersionsOutput
 	simulatePrincipalPolicyResult map[string]*iam.SimulatePrincipalPolicyOutput
+	simulateCustomPolicyResult    *iam.SimulateCustomPolicyOutput
 	user                          map[string]*iam.GetUserOutput
 	group                         map[string]*iam.GetGroupOutput
 	role                          map[string]*iam.GetRoleOutput
 	contextKeys                   map[string]*iam.GetContextKeysForPrincipalPolicyOutput
+	oidcProviders                 map[string]*iam.GetOpenIDConnectProviderOutput
+	serviceLastAccessed           map[string]*iam.GetServiceLastAccessedDetailsOutput
+	accessKeys                    map[string]*iam.ListAccessKeysOutput
+	accessKeyLastUsed             map[string]*iam.GetAccessKeyLastUsedOutput
+	mfaDevices                    map[string]*iam.ListMFADevicesOutput
+	credentialReport              string
+	instanceProfiles              map[string]*iam.GetInstanceProfileOutput
+	accountSummary                *iam.GetAccountSummaryOutput
+	inlinePolicies                map[string]map[string]string
 }
 
 func (m iamApiMock) GetPolicy(ctx context.Context, params *iam.GetPolicyInput, optFns ...func(*iam.Options)) (*iam.GetPolicyOutput, error) {
@@ -35,9 +49,16 @@ func (m iamApiMock) GetPolicy(ctx context.Context, params *iam.GetPolicyInput, o
 }
 
 func (m iamApiMock) GetPolicyVersion(ctx context.Context, params *iam.GetPolicyVersionInput, optFns ...func(*iam.Options)) (*iam.GetPolicyVersionOutput, error) {
+	if versions, ok := m.policyVersionsById[*params.PolicyArn]; ok {
+		return versions[*params.VersionId], nil
+	}
 	return m.policyVersions[*params.PolicyArn], nil
 }
 
+func (m iamApiMock) ListPolicyVersions(ctx context.Context, params *iam.ListPolicyVersionsInput, optFns ...func(*iam.Options)) (*iam.ListPolicyVersionsOutput, error) {
+	return m.listPolicyVersions[*params.PolicyArn], nil
+}
+
 func (m iamApiMock) ListAttachedGroupPolicies(ctx context.Context, params *iam.ListAttachedGroupPoliciesInput, optFns ...func(*iam.Options)) (*iam.ListAttachedGroupPoliciesOutput, error) {
 	return m.attachedGroupPolicies[*params.GroupName], nil
 }
@@ -54,6 +75,10 @@ func (m iamApiMock) SimulatePrincipalPolicy(ctx context.Context, params *iam.Sim
 	return m.simulatePrincipalPolicyResult[*params.PolicySourceArn], nil
 }
 
+func (m iamApiMock) SimulateCustomPolicy(ctx context.Context, params *iam.SimulateCustomPolicyInput, optFns ...func(*iam.Options)) (*iam.SimulateCustomPolicyOutput, error) {
+	return m.simulateCustomPolicyResult, nil
+}
+
 func (m iamApiMock) GetUser(ctx context.Context, params *iam.GetUserInput, optFns ...func(*iam.Options)) (*iam.GetUserOutput, error) {
 	return m.user[*params.UserName], nil
 }
@@ -63,13 +88,101 @@ func (m iamApiMock) GetGroup(ctx context.Context, params *iam.GetGroupInput, opt
 }
 
 func (m iamApiMock) GetRole(ctx context.Context, params *iam.GetRoleInput, optFns ...func(*iam.Options)) (*iam.GetRoleOutput, error) {
-	return m.role[*params.RoleName], nil
+	role, ok := m.role[*params.RoleName]
+	if !ok {
+		return nil, &iamtypes.NoSuchEntityException{}
+	}
+	return role, nil
 }
 
 func (m iamApiMock) GetContextKeysForPrincipalPolicy(ctx context.Context, params *iam.GetContextKeysForPrincipalPolicyInput, optFns ...func(*iam.Options)) (*iam.GetContextKeysForPrincipalPolicyOutput, error) {
 	return m.contextKeys[*params.PolicySourceArn], nil
 }
 
+func (m iamApiMock) GetOpenIDConnectProvider(ctx context.Context, params *iam.GetOpenIDConnectProviderInput, optFns ...func(*iam.Options)) (*iam.GetOpenIDConnectProviderOutput, error) {
+	provider, ok := m.oidcProviders[*params.OpenIDConnectProviderArn]
+	if !ok {
+		return nil, &iamtypes.NoSuchEntityException{}
+	}
+	return provider, nil
+}
+
+func (m iamApiMock) GenerateServiceLastAccessedDetails(ctx context.Context, params *iam.GenerateServiceLastAccessedDetailsInput, optFns ...func(*iam.Options)) (*iam.GenerateServiceLastAccessedDetailsOutput, error) {
+	return &iam.GenerateServiceLastAccessedDetailsOutput{JobId: params.Arn}, nil
+}
+
+func (m iamApiMock) GetServiceLastAccessedDetails(ctx context.Context, params *iam.GetServiceLastAccessedDetailsInput, optFns ...func(*iam.Options)) (*iam.GetServiceLastAccessedDetailsOutput, error) {
+	return m.serviceLastAccessed[*params.JobId], nil
+}
+
+func (m iamApiMock) ListAccessKeys(ctx context.Context, params *iam.ListAccessKeysInput, optFns ...func(*iam.Options)) (*iam.ListAccessKeysOutput, error) {
+	return m.accessKeys[*params.UserName], nil
+}
+
+func (m iamApiMock) GetAccessKeyLastUsed(ctx context.Context, params *iam.GetAccessKeyLastUsedInput, optFns ...func(*iam.Options)) (*iam.GetAccessKeyLastUsedOutput, error) {
+	return m.accessKeyLastUsed[*params.AccessKeyId], nil
+}
+
+func (m iamApiMock) ListMFADevices(ctx context.Context, params *iam.ListMFADevicesInput, optFns ...func(*iam.Options)) (*iam.ListMFADevicesOutput, error) {
+	return m.mfaDevices[*params.UserName], nil
+}
+
+func (m iamApiMock) GenerateCredentialReport(ctx context.Context, params *iam.GenerateCredentialReportInput, optFns ...func(*iam.Options)) (*iam.GenerateCredentialReportOutput, error) {
+	if m.credentialReport == "" {
+		return &iam.GenerateCredentialReportOutput{State: iamtypes.ReportStateTypeStarted}, nil
+	}
+	return &iam.GenerateCredentialReportOutput{State: iamtypes.ReportStateTypeComplete}, nil
+}
+
+func (m iamApiMock) GetCredentialReport(ctx context.Context, params *iam.GetCredentialReportInput, optFns ...func(*iam.Options)) (*iam.GetCredentialReportOutput, error) {
+	return &iam.GetCredentialReportOutput{Content: []byte(m.credentialReport)}, nil
+}
+
+func (m iamApiMock) GetInstanceProfile(ctx context.Context, params *iam.GetInstanceProfileInput, optFns ...func(*iam.Options)) (*iam.GetInstanceProfileOutput, error) {
+	instanceProfile, ok := m.instanceProfiles[*params.InstanceProfileName]
+	if !ok {
+		return nil, &iamtypes.NoSuchEntityException{}
+	}
+	return instanceProfile, nil
+}
+
+func (m iamApiMock) GetAccountSummary(ctx context.Context, params *iam.GetAccountSummaryInput, optFns ...func(*iam.Options)) (*iam.GetAccountSummaryOutput, error) {
+	return m.accountSummary, nil
+}
+
+func (m iamApiMock) listInlinePolicies(principal string) []string {
+	policyNames := make([]string, 0)
+	for policyName := range m.inlinePolicies[principal] {
+		policyNames = append(policyNames, policyName)
+	}
+	slices.Sort(policyNames)
+	return policyNames
+}
+
+func (m iamApiMock) ListRolePolicies(ctx context.Context, params *iam.ListRolePoliciesInput, optFns ...func(*iam.Options)) (*iam.ListRolePoliciesOutput, error) {
+	return &iam.ListRolePoliciesOutput{PolicyNames: m.listInlinePolicies(*params.RoleName)}, nil
+}
+
+func (m iamApiMock) ListUserPolicies(ctx context.Context, params *iam.ListUserPoliciesInput, optFns ...func(*iam.Options)) (*iam.ListUserPoliciesOutput, error) {
+	return &iam.ListUserPoliciesOutput{PolicyNames: m.listInlinePolicies(*params.UserName)}, nil
+}
+
+func (m iamApiMock) ListGroupPolicies(ctx context.Context, params *iam.ListGroupPoliciesInput, optFns ...func(*iam.Options)) (*iam.ListGroupPoliciesOutput, error) {
+	return &iam.ListGroupPoliciesOutput{PolicyNames: m.listInlinePolicies(*params.GroupName)}, nil
+}
+
+func (m iamApiMock) GetRolePolicy(ctx context.Context, params *iam.GetRolePolicyInput, optFns ...func(*iam.Options)) (*iam.GetRolePolicyOutput, error) {
+	return &iam.GetRolePolicyOutput{PolicyDocument: util.Ptr(m.inlinePolicies[*params.RoleName][*params.PolicyName])}, nil
+}
+
+func (m iamApiMock) GetUserPolicy(ctx context.Context, params *iam.GetUserPolicyInput, optFns ...func(*iam.Options)) (*iam.GetUserPolicyOutput, error) {
+	return &iam.GetUserPolicyOutput{PolicyDocument: util.Ptr(m.inlinePolicies[*params.UserName][*params.PolicyName])}, nil
+}
+
+func (m iamApiMock) GetGroupPolicy(ctx context.Context, params *iam.GetGroupPolicyInput, optFns ...func(*iam.Options)) (*iam.GetGroupPolicyOutput, error) {
+	return &iam.GetGroupPolicyOutput{PolicyDocument: util.Ptr(m.inlinePolicies[*params.GroupName][*params.PolicyName])}, nil
+}
+
 const (
 	policyDocumentOutput1 string = `{
 		"Version": "2012-10-17",
@@ -479,7 +592,7 @@ var iamService = NewIAMRuleService(logr.Logger{}, iamApiMock{
 			ContextKeyNames: []string{"aws:PrincipalAccount", "aws:PrincipalArn"},
 		},
 	},
-})
+}, nil, nil, nil)
 
 type testCase struct {
 	name           string
@@ -867,6 +980,39 @@ func TestIAMRoleValidation(t *testing.T) {
 				State: util.Ptr(vapi.ValidationFailed),
 			},
 		},
+		{
+			name: "Fail (SCP & unknown action in IAM action catalog)",
+			rule: v1alpha1.IamRoleRule{
+				IamRoleName: "iamRole6",
+				Policies: []v1alpha1.PolicyDocument{
+					{
+						Name:    "iamPolicy",
+						Version: "1",
+						Statements: []v1alpha1.StatementEntry{
+							{
+								Effect:    "Allow",
+								Actions:   []string{"ec2:DescribeInstances", "ec2:DescribeSubnet"},
+								Resources: []string{"*"},
+							},
+						},
+					},
+				},
+			},
+			expectedResult: types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "aws-iam-role-policy",
+					ValidationRule: "validation-iamRole6",
+					Message:        "One or more required SCP permissions was not found, or a condition was not met",
+					Details:        []string{},
+					Failures: []string{
+						"v1alpha1.IamRoleRule iamRole6 policy iamPolicy has unknown action ec2:DescribeSubnet (IAM action catalog 2024-03-01)",
+						"Action: ec2:DescribeInstances is denied due to an Organization level SCP policy for role: iamRole6",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
 	}
 	for _, c := range cs {
 		result, err := iamService.ReconcileIAMRoleRule(c.rule)
@@ -1251,9 +1397,223 @@ func TestIAMPolicyValidation(t *testing.T) {
 				State: util.Ptr(vapi.ValidationSucceeded),
 			},
 		},
+		{
+			name: "Pass (excess permissions, warn)",
+			rule: v1alpha1.IamPolicyRule{
+				IamPolicyARN: "arn:aws:iam::123456789012:role/iamRoleArn2",
+				Policies: []v1alpha1.PolicyDocument{
+					{
+						Name:    "iamPolicy",
+						Version: "1",
+						Statements: []v1alpha1.StatementEntry{
+							{
+								Effect:    "Allow",
+								Actions:   []string{"ec2:DescribeInstances"},
+								Resources: []string{"*"},
+							},
+						},
+					},
+				},
+				ExcessPermissions: v1alpha1.ExcessPermissionsWarn,
+			},
+			expectedResult: types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "aws-iam-policy",
+					ValidationRule: "validation-arn:aws:iam::123456789012:role/iamRoleArn2",
+					Message:        "All required aws-iam-policy permissions were found",
+					Details: []string{
+						"v1alpha1.IamPolicyRule arn:aws:iam::123456789012:role/iamRoleArn2 granted wildcard action(s) for all services: [*:*]",
+					},
+					Failures: nil,
+					Status:   corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Fail (explicitly denied action within required wildcard)",
+			rule: v1alpha1.IamPolicyRule{
+				IamPolicyARN: "arn:aws:iam::123456789012:role/iamRoleArn5",
+				Policies: []v1alpha1.PolicyDocument{
+					{
+						Name:    "iamPolicy",
+						Version: "1",
+						Statements: []v1alpha1.StatementEntry{
+							{
+								Effect:    "Allow",
+								Actions:   []string{"ec2:Describe*"},
+								Resources: []string{"*"},
+							},
+						},
+					},
+				},
+			},
+			expectedResult: types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "aws-iam-policy",
+					ValidationRule: "validation-arn:aws:iam::123456789012:role/iamRoleArn5",
+					Message:        "One or more required IAM permissions was not found, or a condition was not met",
+					Details:        []string{},
+					Failures: []string{
+						"v1alpha1.IamPolicyRule arn:aws:iam::123456789012:role/iamRoleArn5 missing action(s): [ec2:DescribeInstances] for resource * from policy iamPolicy",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Fail (excess permissions, fail)",
+			rule: v1alpha1.IamPolicyRule{
+				IamPolicyARN: "arn:aws:iam::123456789012:role/iamRoleArn5",
+				Policies: []v1alpha1.PolicyDocument{
+					{
+						Name:    "iamPolicy",
+						Version: "1",
+						Statements: []v1alpha1.StatementEntry{
+							{
+								Effect:    "Allow",
+								Actions:   []string{"ec2:*", "s3:ListBuckets"},
+								Resources: []string{"*"},
+							},
+						},
+					},
+				},
+				ExcessPermissions: v1alpha1.ExcessPermissionsFail,
+			},
+			expectedResult: types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "aws-iam-policy",
+					ValidationRule: "validation-arn:aws:iam::123456789012:role/iamRoleArn5",
+					Message:        "One or more granted IAM permissions was not required",
+					Details:        []string{},
+					Failures: []string{
+						"v1alpha1.IamPolicyRule arn:aws:iam::123456789012:role/iamRoleArn5 granted wildcard action(s) for service iam: [iam:*Group*]",
+						"v1alpha1.IamPolicyRule arn:aws:iam::123456789012:role/iamRoleArn5 granted wildcard action(s) for service organizations: [organizations:*Organizations]",
+						"v1alpha1.IamPolicyRule arn:aws:iam::123456789012:role/iamRoleArn5 granted wildcard action(s) for service s3: [s3:List*]",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Fail (excess permissions & missing permission)",
+			rule: v1alpha1.IamPolicyRule{
+				IamPolicyARN: "arn:aws:iam::123456789012:role/iamRoleArn1",
+				Policies: []v1alpha1.PolicyDocument{
+					{
+						Name:    "iamPolicy",
+						Version: "1",
+						Statements: []v1alpha1.StatementEntry{
+							{
+								Effect:    "Allow",
+								Actions:   []string{"s3:GetBuckets"},
+								Resources: []string{"*"},
+							},
+						},
+					},
+				},
+				ExcessPermissions: v1alpha1.ExcessPermissionsFail,
+			},
+			expectedResult: types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "aws-iam-policy",
+					ValidationRule: "validation-arn:aws:iam::123456789012:role/iamRoleArn1",
+					Message:        "One or more required IAM permissions was not found, or a condition was not met",
+					Details:        []string{},
+					Failures: []string{
+						"v1alpha1.IamPolicyRule arn:aws:iam::123456789012:role/iamRoleArn1 missing action(s): [s3:GetBuckets] for resource * from policy iamPolicy",
+						"v1alpha1.IamPolicyRule arn:aws:iam::123456789012:role/iamRoleArn1 granted excess action(s) for service ec2: [ec2:DescribeInstances]",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
 	}
 	for _, c := range cs {
 		result, err := iamService.ReconcileIAMPolicyRule(c.rule)
 		util.CheckTestCase(t, result, c.expectedResult, err, c.expectedError)
 	}
 }
+
+func TestGetAccountIDFromARN(t *testing.T) {
+	cs := map[string]string{
+		"arn:aws:iam::123456789012:role/path/role":       "123456789012",
+		"arn:aws-us-gov:iam::123456789012:user/user":     "123456789012",
+		"arn:aws-cn:iam::123456789012:role/service-role": "123456789012",
+	}
+	for arn, expected := range cs {
+		accountID, err := getAccountIDFromARN(arn)
+		if err != nil || accountID != expected {
+			t.Errorf("%s: expected account ID %s, got %s (err: %v)", arn, expected, accountID, err)
+		}
+	}
+	if _, err := getAccountIDFromARN("not-an-arn"); err == nil {
+		t.Error("expected error for invalid ARN")
+	}
+}
+
+func TestIAMActionCovers(t *testing.T) {
+	cs := []struct {
+		action   string
+		other    string
+		expected bool
+	}{
+		{"*", "ec2:DescribeInstances", true},
+		{"ec2:*", "ec2:DescribeInstances", true},
+		{"ec2:*", "s3:GetObject", false},
+		{"ec2:Describe*", "ec2:DescribeInstances", true},
+		{"ec2:*Instances", "ec2:DescribeInstances", true},
+		{"iam:*Group*", "iam:ListGroupPolicies", true},
+		{"ec2:Describe*s", "ec2:DescribeInstances", true},
+		{"ec2:Describe*s", "ec2:DescribeInstanceAttribute", false},
+		{"ec2:Describe?nstances", "ec2:DescribeInstances", true},
+		{"EC2:describeinstances", "ec2:DescribeInstances", true},
+		{"ec2:Describe*", "ec2:DescribeI*", true},
+		{"ec2:DescribeI*", "ec2:Describe*", false},
+		{"ec2:DescribeInstances", "ec2:RunInstances", false},
+	}
+	for _, c := range cs {
+		action := toIAMAction(c.action)
+		if got := action.Covers(toIAMAction(c.other)); got != c.expected {
+			t.Errorf("%s covers %s: expected %v, got %v", c.action, c.other, c.expected, got)
+		}
+	}
+}
+
+func TestComputeExcessPermissions(t *testing.T) {
+	rule := v1alpha1.IamPolicyRule{
+		IamPolicyARN: "arn:aws:iam::123456789012:policy/policy",
+		Policies: []v1alpha1.PolicyDocument{
+			{
+				Name:    "policy",
+				Version: "1",
+				Statements: []v1alpha1.StatementEntry{
+					{Effect: "Allow", Actions: []string{"ec2:Describe*"}, Resources: []string{"*"}},
+				},
+			},
+		},
+		ExcessPermissions: v1alpha1.ExcessPermissionsWarn,
+	}
+	policyDocuments := []*awspolicy.Policy{
+		{
+			Statements: []awspolicy.Statement{
+				{Effect: "Allow", Action: []string{"ec2:DescribeI*", "EC2:describeinstances", "ec2:RunInstances"}, Resource: []string{"*"}},
+				{Effect: "Allow", NotAction: []string{"iam:*"}, Resource: []string{"*"}},
+				{Effect: "Deny", NotAction: []string{"s3:*"}, Resource: []string{"*"}},
+			},
+		},
+	}
+	vr := &types.ValidationRuleResult{Condition: &vapi.ValidationCondition{Details: []string{}}}
+	computeExcessPermissions(rule, policyDocuments, vr)
+
+	expected := []string{
+		"v1alpha1.IamPolicyRule arn:aws:iam::123456789012:policy/policy granted all actions except [iam:*] via a NotAction statement",
+		"v1alpha1.IamPolicyRule arn:aws:iam::123456789012:policy/policy granted excess action(s) for service ec2: [ec2:RunInstances]",
+	}
+	if !slices.Equal(vr.Condition.Details, expected) {
+		t.Errorf("expected details %v, got %v", expected, vr.Condition.Details)
+	}
+}
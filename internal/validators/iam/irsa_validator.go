@@ -0,0 +1,218 @@
+package iam
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	awspolicy "github.com/L30Bola/aws-policy"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamtypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"golang.org/x/exp/slices"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-aws/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-aws/internal/constants"
+	aws_utils "github.com/spectrocloud-labs/validator-plugin-aws/internal/utils/aws"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/types"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+// ReconcileIAMIRSARule reconciles an IAM roles for service accounts (IRSA) validation rule from an AWSValidator config
+func (s *IAMRuleService) ReconcileIAMIRSARule(rule v1alpha1.IamIrsaRule) (*types.ValidationRuleResult, error) {
+
+	// Build the default ValidationResult for this IAM rule
+	vr := buildValidationResult(rule, constants.ValidationTypeIAMIRSA)
+
+	role, err := s.iamSvc.GetRole(context.Background(), &iam.GetRoleInput{
+		RoleName: util.Ptr(rule.Name()),
+	})
+	if err != nil {
+		return vr, err
+	}
+
+	irsaFailures, err := s.irsaFailures(rule, role.Role)
+	if err != nil {
+		return vr, err
+	}
+
+	// Compare the role's attached policies against the required permissions
+	if err := s.validateRolePolicies(rule, role.Role, vr); err != nil {
+		return vr, err
+	}
+
+	if len(irsaFailures) > 0 {
+		vr.State = util.Ptr(vapi.ValidationFailed)
+		vr.Condition.Failures = append(irsaFailures, vr.Condition.Failures...)
+		vr.Condition.Message = "One or more IRSA requirements was not met"
+		vr.Condition.Status = corev1.ConditionFalse
+	}
+
+	return vr, nil
+}
+
+// irsaFailures verifies the IAM OIDC provider for an IRSA rule's issuer and the IAM role's trust policy
+func (s *IAMRuleService) irsaFailures(rule v1alpha1.IamIrsaRule, role *iamtypes.Role) ([]string, error) {
+	failures := make([]string, 0)
+
+	issuer := oidcIssuerHostPath(rule.OidcIssuerURL)
+	providerArn, err := oidcProviderArn(*role.Arn, issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	provider, err := s.iamSvc.GetOpenIDConnectProvider(context.Background(), &iam.GetOpenIDConnectProviderInput{
+		OpenIDConnectProviderArn: util.Ptr(providerArn),
+	})
+	if err != nil {
+		var nse *iamtypes.NoSuchEntityException
+		if !errors.As(err, &nse) {
+			s.log.V(0).Error(err, "failed to get IAM OIDC provider", "providerArn", providerArn)
+			return nil, err
+		}
+		failures = append(failures, fmt.Sprintf("IAM OIDC provider %s not found for issuer %s", providerArn, rule.OidcIssuerURL))
+	} else if !slices.Contains(provider.ClientIDList, constants.IRSAAudience) {
+		failures = append(failures, fmt.Sprintf("IAM OIDC provider %s missing audience %s", providerArn, constants.IRSAAudience))
+	}
+
+	subject := fmt.Sprintf("system:serviceaccount:%s:%s", rule.ServiceAccountNamespace, rule.ServiceAccountName)
+	trusted := false
+	if role.AssumeRolePolicyDocument != nil {
+		trustPolicy, err := decodePolicyDocument(*role.AssumeRolePolicyDocument)
+		if err != nil {
+			s.log.V(0).Error(err, "failed to decode IAM role trust policy", "role", rule.Name())
+			return nil, err
+		}
+		trusted, err = trustsServiceAccount(trustPolicy, providerArn, issuer, subject)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("Trust policy for IAM role %s: %v", rule.Name(), err))
+		}
+	}
+	if !trusted {
+		failures = append(failures, fmt.Sprintf(
+			"Trust policy for IAM role %s does not allow %s to assume it via IAM OIDC provider %s",
+			rule.Name(), subject, providerArn,
+		))
+	}
+
+	return failures, nil
+}
+
+// oidcIssuerHostPath strips the scheme and any trailing slash from an OIDC issuer URL
+func oidcIssuerHostPath(issuerURL string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(issuerURL, "https://"), "/")
+}
+
+// oidcProviderArn derives the ARN of the IAM OIDC provider for an issuer in the same partition & account as an IAM role
+func oidcProviderArn(roleArn, issuer string) (string, error) {
+	a, err := aws_utils.ParseARN(roleArn)
+	if err != nil {
+		return "", fmt.Errorf("invalid IAM role ARN %s", roleArn)
+	}
+	return aws_utils.IAMArn(a.Partition, a.AccountID, "oidc-provider", issuer), nil
+}
+
+// decodePolicyDocument decodes a URL-encoded IAM policy document
+func decodePolicyDocument(document string) (*awspolicy.Policy, error) {
+	policyUnescaped, err := url.QueryUnescape(document)
+	if err != nil {
+		return nil, err
+	}
+	policyDocument := &awspolicy.Policy{}
+	if err := policyDocument.UnmarshalJSON([]byte(policyUnescaped)); err != nil {
+		return nil, err
+	}
+	return policyDocument, nil
+}
+
+// trustsServiceAccount determines whether a trust policy allows a service account to assume a role via an IAM OIDC provider.
+// An error is returned if a matching statement can't be evaluated and no other statement trusts the service account.
+func trustsServiceAccount(trustPolicy *awspolicy.Policy, providerArn, issuer, subject string) (bool, error) {
+	var unsupported error
+	for _, s := range trustPolicy.Statements {
+		if s.Effect != "Allow" || !slices.Contains(s.Principal["Federated"], providerArn) {
+			continue
+		}
+		if !slices.ContainsFunc(s.Action, func(a string) bool {
+			return a == constants.IRSAAssumeRoleAction || a == "sts:*" || a == constants.IAMWildcard
+		}) {
+			continue
+		}
+		audAllowed, err := conditionAllows(s.Condition, fmt.Sprintf("%s:aud", issuer), constants.IRSAAudience)
+		if err != nil {
+			unsupported = err
+			continue
+		}
+		subAllowed, err := conditionAllows(s.Condition, fmt.Sprintf("%s:sub", issuer), subject)
+		if err != nil {
+			unsupported = err
+			continue
+		}
+		if audAllowed && subAllowed {
+			return true, nil
+		}
+	}
+	return false, unsupported
+}
+
+// conditionAllows determines whether the string conditions for a key, if any, match a value.
+// The ForAnyValue / ForAllValues set qualifiers and the IfExists suffix are ignored, as the OIDC
+// aud & sub keys are always present and single-valued in a service account token.
+func conditionAllows(condition awspolicy.Condition, key, value string) (bool, error) {
+	for operator, keys := range condition {
+		values, ok := keys[key]
+		if !ok {
+			continue
+		}
+		op := strings.TrimSuffix(operator, "IfExists")
+		op = strings.TrimPrefix(strings.TrimPrefix(op, "ForAnyValue:"), "ForAllValues:")
+
+		var matches func(string) bool
+		switch op {
+		case "StringEquals", "StringNotEquals":
+			matches = func(v string) bool { return v == value }
+		case "StringEqualsIgnoreCase", "StringNotEqualsIgnoreCase":
+			matches = func(v string) bool { return strings.EqualFold(v, value) }
+		case "StringLike", "StringNotLike":
+			matches = func(pattern string) bool { return stringLike(pattern, value) }
+		default:
+			return false, fmt.Errorf("unsupported condition operator %s for key %s", operator, key)
+		}
+		negated := strings.HasPrefix(op, "StringNot")
+		if slices.ContainsFunc(values, matches) == negated {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// stringLike matches a value against an IAM StringLike pattern, in which * matches any sequence of
+// characters, including /, and ? matches any single character. No other characters are special.
+func stringLike(pattern, value string) bool {
+	p, v := []rune(pattern), []rune(value)
+	pi, vi := 0, 0
+	star, match := -1, 0
+	for vi < len(v) {
+		switch {
+		case pi < len(p) && (p[pi] == '?' || p[pi] == v[vi]):
+			pi++
+			vi++
+		case pi < len(p) && p[pi] == '*':
+			star, match = pi, vi
+			pi++
+		case star >= 0:
+			// backtrack: let the last * consume one more character
+			match++
+			pi, vi = star+1, match
+		default:
+			return false
+		}
+	}
+	for pi < len(p) && p[pi] == '*' {
+		pi++
+	}
+	return pi == len(p)
+}
@@ -0,0 +1,319 @@
+package iam
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamtypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-aws/api/v1alpha1"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/types"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+const (
+	oidcIssuer          string = "oidc.eks.us-west-2.amazonaws.com/id/EXAMPLED539D4633E53DE1B71EXAMPLE"
+	oidcProviderArnIRSA string = "arn:aws:iam::123456789012:oidc-provider/" + oidcIssuer
+
+	trustPolicyDocument1 string = `{
+		"Version": "2012-10-17",
+		"Statement": [
+			{
+				"Effect": "Allow",
+				"Principal": {
+					"Federated": "` + oidcProviderArnIRSA + `"
+				},
+				"Action": "sts:AssumeRoleWithWebIdentity",
+				"Condition": {
+					"StringEquals": {
+						"` + oidcIssuer + `:aud": "sts.amazonaws.com",
+						"` + oidcIssuer + `:sub": "system:serviceaccount:kube-system:aws-load-balancer-controller"
+					}
+				}
+			}
+		]
+	}`
+	trustPolicyDocument2 string = `{
+		"Version": "2012-10-17",
+		"Statement": [
+			{
+				"Effect": "Allow",
+				"Principal": {
+					"Federated": "` + oidcProviderArnIRSA + `"
+				},
+				"Action": "sts:AssumeRoleWithWebIdentity",
+				"Condition": {
+					"StringLike": {
+						"` + oidcIssuer + `:sub": "system:serviceaccount:kube-system:*"
+					}
+				}
+			}
+		]
+	}`
+)
+
+var irsaService = NewIAMRuleService(logr.Logger{}, iamApiMock{
+	attachedRolePolicies: map[string]*iam.ListAttachedRolePoliciesOutput{
+		"irsaRole1": {
+			AttachedPolicies: []iamtypes.AttachedPolicy{
+				{
+					PolicyArn:  util.Ptr("arn:aws:iam::123456789012:role/iamRoleArn1"),
+					PolicyName: util.Ptr("iamPolicy"),
+				},
+			},
+		},
+		"irsaRole2": {
+			AttachedPolicies: []iamtypes.AttachedPolicy{
+				{
+					PolicyArn:  util.Ptr("arn:aws:iam::123456789012:role/iamRoleArn1"),
+					PolicyName: util.Ptr("iamPolicy"),
+				},
+			},
+		},
+	},
+	policyArns: map[string]*iam.GetPolicyOutput{
+		"arn:aws:iam::123456789012:role/iamRoleArn1": {
+			Policy: util.Ptr(iamtypes.Policy{
+				DefaultVersionId: util.Ptr("1"),
+			}),
+		},
+	},
+	policyVersions: map[string]*iam.GetPolicyVersionOutput{
+		"arn:aws:iam::123456789012:role/iamRoleArn1": {
+			PolicyVersion: util.Ptr(iamtypes.PolicyVersion{
+				Document: util.Ptr(url.QueryEscape(policyDocumentOutput1)),
+			}),
+		},
+	},
+	role: map[string]*iam.GetRoleOutput{
+		"irsaRole1": {
+			Role: &iamtypes.Role{
+				Arn:                      util.Ptr("arn:aws:iam::123456789012:role/irsaRole1"),
+				AssumeRolePolicyDocument: util.Ptr(url.QueryEscape(trustPolicyDocument1)),
+				RoleName:                 util.Ptr("irsaRole1"),
+				RoleId:                   util.Ptr("irsaRoleID1"),
+			},
+		},
+		"irsaRole2": {
+			Role: &iamtypes.Role{
+				Arn:                      util.Ptr("arn:aws:iam::123456789012:role/irsaRole2"),
+				AssumeRolePolicyDocument: util.Ptr(url.QueryEscape(trustPolicyDocument2)),
+				RoleName:                 util.Ptr("irsaRole2"),
+				RoleId:                   util.Ptr("irsaRoleID2"),
+			},
+		},
+	},
+	simulatePrincipalPolicyResult: map[string]*iam.SimulatePrincipalPolicyOutput{
+		"arn:aws:iam::123456789012:role/irsaRole1": {},
+		"arn:aws:iam::123456789012:role/irsaRole2": {},
+	},
+	oidcProviders: map[string]*iam.GetOpenIDConnectProviderOutput{
+		oidcProviderArnIRSA: {
+			ClientIDList: []string{"sts.amazonaws.com"},
+			Url:          util.Ptr(oidcIssuer),
+		},
+	},
+}, nil, nil, nil)
+
+type irsaTestCase struct {
+	name           string
+	rule           v1alpha1.IamIrsaRule
+	expectedResult types.ValidationRuleResult
+	expectedError  error
+}
+
+func TestIAMIRSAValidation(t *testing.T) {
+	policies := []v1alpha1.PolicyDocument{
+		{
+			Name:    "iamPolicy",
+			Version: "1",
+			Statements: []v1alpha1.StatementEntry{
+				{
+					Effect:    "Allow",
+					Actions:   []string{"ec2:DescribeInstances"},
+					Resources: []string{"*"},
+				},
+			},
+		},
+	}
+	cs := []irsaTestCase{
+		{
+			name: "Pass (basic)",
+			rule: v1alpha1.IamIrsaRule{
+				IamRoleName:             "irsaRole1",
+				OidcIssuerURL:           "https://" + oidcIssuer,
+				ServiceAccountNamespace: "kube-system",
+				ServiceAccountName:      "aws-load-balancer-controller",
+				Policies:                policies,
+			},
+			expectedResult: types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "aws-iam-irsa",
+					ValidationRule: "validation-irsaRole1",
+					Message:        "All required aws-iam-irsa permissions were found",
+					Details:        []string{},
+					Failures:       nil,
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Pass (StringLike subject)",
+			rule: v1alpha1.IamIrsaRule{
+				IamRoleName:             "irsaRole2",
+				OidcIssuerURL:           "https://" + oidcIssuer + "/",
+				ServiceAccountNamespace: "kube-system",
+				ServiceAccountName:      "ebs-csi-controller-sa",
+				Policies:                policies,
+			},
+			expectedResult: types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "aws-iam-irsa",
+					ValidationRule: "validation-irsaRole2",
+					Message:        "All required aws-iam-irsa permissions were found",
+					Details:        []string{},
+					Failures:       nil,
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Fail (service account not trusted)",
+			rule: v1alpha1.IamIrsaRule{
+				IamRoleName:             "irsaRole1",
+				OidcIssuerURL:           "https://" + oidcIssuer,
+				ServiceAccountNamespace: "default",
+				ServiceAccountName:      "aws-load-balancer-controller",
+				Policies:                policies,
+			},
+			expectedResult: types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "aws-iam-irsa",
+					ValidationRule: "validation-irsaRole1",
+					Message:        "One or more IRSA requirements was not met",
+					Details:        []string{},
+					Failures: []string{
+						"Trust policy for IAM role irsaRole1 does not allow system:serviceaccount:default:aws-load-balancer-controller to assume it via IAM OIDC provider " + oidcProviderArnIRSA,
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Fail (missing OIDC provider & permission)",
+			rule: v1alpha1.IamIrsaRule{
+				IamRoleName:             "irsaRole1",
+				OidcIssuerURL:           "https://oidc.eks.us-west-2.amazonaws.com/id/OTHER",
+				ServiceAccountNamespace: "kube-system",
+				ServiceAccountName:      "aws-load-balancer-controller",
+				Policies: []v1alpha1.PolicyDocument{
+					{
+						Name:    "iamPolicy",
+						Version: "1",
+						Statements: []v1alpha1.StatementEntry{
+							{
+								Effect:    "Allow",
+								Actions:   []string{"s3:GetBuckets"},
+								Resources: []string{"*"},
+							},
+						},
+					},
+				},
+			},
+			expectedResult: types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "aws-iam-irsa",
+					ValidationRule: "validation-irsaRole1",
+					Message:        "One or more IRSA requirements was not met",
+					Details:        []string{},
+					Failures: []string{
+						"IAM OIDC provider arn:aws:iam::123456789012:oidc-provider/oidc.eks.us-west-2.amazonaws.com/id/OTHER not found for issuer https://oidc.eks.us-west-2.amazonaws.com/id/OTHER",
+						"Trust policy for IAM role irsaRole1 does not allow system:serviceaccount:kube-system:aws-load-balancer-controller to assume it via IAM OIDC provider arn:aws:iam::123456789012:oidc-provider/oidc.eks.us-west-2.amazonaws.com/id/OTHER",
+						"v1alpha1.IamIrsaRule irsaRole1 missing action(s): [s3:GetBuckets] for resource * from policy iamPolicy",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+	}
+	for _, c := range cs {
+		result, err := irsaService.ReconcileIAMIRSARule(c.rule)
+		util.CheckTestCase(t, result, c.expectedResult, err, c.expectedError)
+	}
+}
+
+func TestConditionAllows(t *testing.T) {
+	const (
+		key     = oidcIssuer + ":sub"
+		subject = "system:serviceaccount:kube-system:aws-load-balancer-controller"
+	)
+	cs := []struct {
+		name          string
+		operator      string
+		values        []string
+		expected      bool
+		expectedError bool
+	}{
+		{"StringEquals match", "StringEquals", []string{subject}, true, false},
+		{"StringEquals mismatch", "StringEquals", []string{"system:serviceaccount:default:other"}, false, false},
+		{"StringNotEquals excludes", "StringNotEquals", []string{subject}, false, false},
+		{"StringNotEquals allows", "StringNotEquals", []string{"system:serviceaccount:default:other"}, true, false},
+		{"StringEqualsIgnoreCase match", "StringEqualsIgnoreCase", []string{"SYSTEM:ServiceAccount:kube-system:aws-load-balancer-controller"}, true, false},
+		{"StringNotEqualsIgnoreCase excludes", "StringNotEqualsIgnoreCase", []string{"SYSTEM:ServiceAccount:kube-system:aws-load-balancer-controller"}, false, false},
+		{"StringLike match", "StringLike", []string{"system:serviceaccount:kube-system:*"}, true, false},
+		{"StringNotLike excludes", "StringNotLike", []string{"system:serviceaccount:kube-system:*"}, false, false},
+		{"StringLike ? match", "StringLike", []string{"system:serviceaccount:kube-system:aws-load-balancer-controlle?"}, true, false},
+		{"ForAnyValue:StringLike match", "ForAnyValue:StringLike", []string{"system:serviceaccount:*"}, true, false},
+		{"ForAllValues:StringNotEquals excludes", "ForAllValues:StringNotEquals", []string{subject}, false, false},
+		{"StringEqualsIfExists match", "StringEqualsIfExists", []string{subject}, true, false},
+		{"unsupported operator", "ArnLike", []string{subject}, false, true},
+	}
+	for _, c := range cs {
+		allowed, err := conditionAllows(map[string]map[string][]string{
+			c.operator: {key: c.values},
+		}, key, subject)
+		if allowed != c.expected || (err != nil) != c.expectedError {
+			t.Errorf("%s: expected (%v, error: %v), got (%v, %v)", c.name, c.expected, c.expectedError, allowed, err)
+		}
+	}
+	if allowed, err := conditionAllows(nil, key, subject); !allowed || err != nil {
+		t.Errorf("no condition: expected (true, nil), got (%v, %v)", allowed, err)
+	}
+}
+
+func TestStringLike(t *testing.T) {
+	cs := []struct {
+		pattern  string
+		value    string
+		expected bool
+	}{
+		{"system:serviceaccount:ns/*", "system:serviceaccount:ns/a/b", true},
+		{"system:serviceaccount:*", "system:serviceaccount:kube-system:sa", true},
+		{"*", "", true},
+		{"", "", true},
+		{"", "a", false},
+		{"sa-?", "sa-1", true},
+		{"sa-?", "sa-12", false},
+		{"sa-[1]", "sa-[1]", true},
+		{"sa-[1]", "sa-1", false},
+		{`sa-\*`, `sa-\x`, true},
+		{"*-controller", "aws-load-balancer-controller", true},
+		{"*-controller", "aws-load-balancer-controller-2", false},
+		{"a*b*c", "axxbyyc", true},
+		{"a*b*c", "axxcyyb", false},
+		{"Sa", "sa", false},
+	}
+	for _, c := range cs {
+		if got := stringLike(c.pattern, c.value); got != c.expected {
+			t.Errorf("stringLike(%q, %q): expected %v, got %v", c.pattern, c.value, c.expected, got)
+		}
+	}
+}
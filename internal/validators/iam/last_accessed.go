@@ -0,0 +1,165 @@
+package iam
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamtypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"golang.org/x/exp/slices"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-aws/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-aws/internal/constants"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/types"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+// lastAccessedJobs holds the IDs of service last accessed jobs that were still in progress, by IAM principal ARN,
+// so that each reconcile reads the job started by a previous reconcile rather than waiting for it to complete
+var lastAccessedJobs = struct {
+	sync.Mutex
+	ids map[string]*string
+}{ids: make(map[string]*string)}
+
+type lastAccessedRule interface {
+	LastAccessedCheck() *v1alpha1.LastAccessedCheck
+}
+
+// checkLastAccessed reports required services that were never accessed by an IAM principal and
+// granted services that were not accessed by the principal within the rule's configured number of days
+func (s *IAMRuleService) checkLastAccessed(rule iamRule, principalArn string, vr *types.ValidationRuleResult) error {
+	lr, ok := rule.(lastAccessedRule)
+	if !ok || lr.LastAccessedCheck() == nil {
+		return nil
+	}
+	check := lr.LastAccessedCheck()
+
+	servicesLastAccessed, err := s.getServicesLastAccessed(principalArn)
+	if err != nil {
+		return err
+	}
+	if servicesLastAccessed == nil {
+		vr.Condition.Details = append(vr.Condition.Details, fmt.Sprintf(
+			"%T %s service last accessed details are still being generated and will be checked on the next reconcile", rule, rule.Name(),
+		))
+		return nil
+	}
+
+	required := make(map[string]bool)
+	for _, p := range rule.IAMPolicies() {
+		for _, st := range p.Statements {
+			for _, a := range st.Actions {
+				if action := toIAMAction(a); action.Service != constants.IAMWildcard {
+					required[action.Service] = true
+				}
+			}
+		}
+	}
+
+	unused := make([]string, 0)
+	cutoff := time.Now().AddDate(0, 0, -check.UnusedDays)
+	for _, sla := range servicesLastAccessed {
+		if sla.ServiceNamespace == nil {
+			continue
+		}
+		service := *sla.ServiceNamespace
+		if required[service] {
+			if sla.LastAuthenticated == nil {
+				unused = append(unused, fmt.Sprintf("%T %s required service %s has never been accessed", rule, rule.Name(), service))
+			}
+			continue
+		}
+		if sla.LastAuthenticated == nil {
+			unused = append(unused, fmt.Sprintf("%T %s granted service %s has never been accessed", rule, rule.Name(), service))
+		} else if sla.LastAuthenticated.Before(cutoff) {
+			unused = append(unused, fmt.Sprintf(
+				"%T %s granted service %s has not been accessed in %d days (last accessed %s)",
+				rule, rule.Name(), service, check.UnusedDays, sla.LastAuthenticated.UTC().Format(time.RFC3339),
+			))
+		}
+	}
+	if len(unused) == 0 {
+		return nil
+	}
+	slices.Sort(unused)
+
+	if !check.Fail {
+		vr.Condition.Details = append(vr.Condition.Details, unused...)
+		return nil
+	}
+	if len(vr.Condition.Failures) == 0 {
+		vr.Condition.Message = "One or more services was not accessed within the specified number of days"
+	}
+	vr.State = util.Ptr(vapi.ValidationFailed)
+	vr.Condition.Failures = append(vr.Condition.Failures, unused...)
+	vr.Condition.Status = corev1.ConditionFalse
+	return nil
+}
+
+// getServicesLastAccessed retrieves the IAM service last accessed details for an IAM principal, starting a job to generate
+// them if there isn't one in progress already. Nil is returned if the job hasn't completed yet.
+func (s *IAMRuleService) getServicesLastAccessed(principalArn string) ([]iamtypes.ServiceLastAccessed, error) {
+	lastAccessedJobs.Lock()
+	jobId, ok := lastAccessedJobs.ids[principalArn]
+	lastAccessedJobs.Unlock()
+
+	if !ok {
+		job, err := s.iamSvc.GenerateServiceLastAccessedDetails(context.Background(), &iam.GenerateServiceLastAccessedDetailsInput{
+			Arn: util.Ptr(principalArn),
+		})
+		if err != nil {
+			s.log.V(0).Error(err, "failed to generate service last accessed details", "arn", principalArn)
+			return nil, err
+		}
+		jobId = job.JobId
+	}
+
+	var marker *string
+	servicesLastAccessed := make([]iamtypes.ServiceLastAccessed, 0)
+	for {
+		output, err := s.iamSvc.GetServiceLastAccessedDetails(context.Background(), &iam.GetServiceLastAccessedDetailsInput{
+			JobId:  jobId,
+			Marker: marker,
+		})
+		if err != nil {
+			s.log.V(0).Error(err, "failed to get service last accessed details", "arn", principalArn, "jobId", jobId)
+			setLastAccessedJob(principalArn, nil)
+			return nil, err
+		}
+
+		switch output.JobStatus {
+		case iamtypes.JobStatusTypeInProgress:
+			setLastAccessedJob(principalArn, jobId)
+			return nil, nil
+		case iamtypes.JobStatusTypeFailed:
+			setLastAccessedJob(principalArn, nil)
+			if output.Error != nil && output.Error.Message != nil {
+				return nil, fmt.Errorf("failed to generate service last accessed details for %s: %s", principalArn, *output.Error.Message)
+			}
+			return nil, fmt.Errorf("failed to generate service last accessed details for %s", principalArn)
+		}
+
+		servicesLastAccessed = append(servicesLastAccessed, output.ServicesLastAccessed...)
+		if output.IsTruncated {
+			marker = output.Marker
+			continue
+		}
+		setLastAccessedJob(principalArn, nil)
+		return servicesLastAccessed, nil
+	}
+}
+
+// setLastAccessedJob records an in-progress service last accessed job for an IAM principal, or clears it if jobId is nil
+func setLastAccessedJob(principalArn string, jobId *string) {
+	lastAccessedJobs.Lock()
+	defer lastAccessedJobs.Unlock()
+	if jobId == nil {
+		delete(lastAccessedJobs.ids, principalArn)
+		return
+	}
+	lastAccessedJobs.ids[principalArn] = jobId
+}
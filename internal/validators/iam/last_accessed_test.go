@@ -0,0 +1,229 @@
+package iam
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamtypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-aws/api/v1alpha1"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/types"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+var (
+	recentlyAccessed = time.Now().AddDate(0, 0, -10)
+	staleAccessed    = time.Now().AddDate(0, 0, -200)
+)
+
+var lastAccessedService = NewIAMRuleService(logr.Logger{}, iamApiMock{
+	attachedRolePolicies: map[string]*iam.ListAttachedRolePoliciesOutput{
+		"lastAccessedRole": {
+			AttachedPolicies: []iamtypes.AttachedPolicy{
+				{
+					PolicyArn:  util.Ptr("arn:aws:iam::123456789012:role/iamRoleArn1"),
+					PolicyName: util.Ptr("iamPolicy"),
+				},
+			},
+		},
+		"pendingRole": {
+			AttachedPolicies: []iamtypes.AttachedPolicy{
+				{
+					PolicyArn:  util.Ptr("arn:aws:iam::123456789012:role/iamRoleArn1"),
+					PolicyName: util.Ptr("iamPolicy"),
+				},
+			},
+		},
+	},
+	policyArns: map[string]*iam.GetPolicyOutput{
+		"arn:aws:iam::123456789012:role/iamRoleArn1": {
+			Policy: util.Ptr(iamtypes.Policy{
+				DefaultVersionId: util.Ptr("1"),
+			}),
+		},
+	},
+	policyVersions: map[string]*iam.GetPolicyVersionOutput{
+		"arn:aws:iam::123456789012:role/iamRoleArn1": {
+			PolicyVersion: util.Ptr(iamtypes.PolicyVersion{
+				Document: util.Ptr(url.QueryEscape(policyDocumentOutput1)),
+			}),
+		},
+	},
+	role: map[string]*iam.GetRoleOutput{
+		"pendingRole": {
+			Role: &iamtypes.Role{
+				Arn:      util.Ptr("arn:aws:iam::123456789012:role/pendingRole"),
+				RoleName: util.Ptr("pendingRole"),
+				RoleId:   util.Ptr("pendingRoleID"),
+			},
+		},
+		"lastAccessedRole": {
+			Role: &iamtypes.Role{
+				Arn:      util.Ptr("arn:aws:iam::123456789012:role/lastAccessedRole"),
+				RoleName: util.Ptr("lastAccessedRole"),
+				RoleId:   util.Ptr("lastAccessedRoleID"),
+			},
+		},
+	},
+	simulatePrincipalPolicyResult: map[string]*iam.SimulatePrincipalPolicyOutput{
+		"arn:aws:iam::123456789012:role/lastAccessedRole": {},
+		"arn:aws:iam::123456789012:role/pendingRole":      {},
+	},
+	serviceLastAccessed: map[string]*iam.GetServiceLastAccessedDetailsOutput{
+		"arn:aws:iam::123456789012:role/pendingRole": {
+			JobStatus: iamtypes.JobStatusTypeInProgress,
+		},
+		"arn:aws:iam::123456789012:role/lastAccessedRole": {
+			JobStatus: iamtypes.JobStatusTypeCompleted,
+			ServicesLastAccessed: []iamtypes.ServiceLastAccessed{
+				{
+					ServiceNamespace:  util.Ptr("ec2"),
+					LastAuthenticated: &recentlyAccessed,
+				},
+				{
+					ServiceNamespace: util.Ptr("kms"),
+				},
+				{
+					ServiceNamespace:  util.Ptr("s3"),
+					LastAuthenticated: &staleAccessed,
+				},
+			},
+		},
+	},
+}, nil, nil, nil)
+
+func TestIAMRoleLastAccessed(t *testing.T) {
+	cs := []testCase{
+		{
+			name: "Pass (unused services reported as details)",
+			rule: v1alpha1.IamRoleRule{
+				IamRoleName: "lastAccessedRole",
+				Policies: []v1alpha1.PolicyDocument{
+					{
+						Name:    "iamPolicy",
+						Version: "1",
+						Statements: []v1alpha1.StatementEntry{
+							{
+								Effect:    "Allow",
+								Actions:   []string{"ec2:DescribeInstances"},
+								Resources: []string{"*"},
+							},
+						},
+					},
+				},
+				LastAccessed: &v1alpha1.LastAccessedCheck{UnusedDays: 90},
+			},
+			expectedResult: types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "aws-iam-role-policy",
+					ValidationRule: "validation-lastAccessedRole",
+					Message:        "All required aws-iam-role-policy permissions were found",
+					Details: []string{
+						"v1alpha1.IamRoleRule lastAccessedRole granted service kms has never been accessed",
+						"v1alpha1.IamRoleRule lastAccessedRole granted service s3 has not been accessed in 90 days (last accessed " + staleAccessed.UTC().Format(time.RFC3339) + ")",
+					},
+					Failures: nil,
+					Status:   corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Fail (required service never accessed)",
+			rule: v1alpha1.IamRoleRule{
+				IamRoleName: "lastAccessedRole",
+				Policies: []v1alpha1.PolicyDocument{
+					{
+						Name:    "iamPolicy",
+						Version: "1",
+						Statements: []v1alpha1.StatementEntry{
+							{
+								Effect:    "Allow",
+								Actions:   []string{"ec2:DescribeInstances", "kms:DescribeKey", "s3:ListBuckets"},
+								Resources: []string{"*"},
+							},
+						},
+					},
+				},
+				LastAccessed: &v1alpha1.LastAccessedCheck{UnusedDays: 300, Fail: true},
+			},
+			expectedResult: types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "aws-iam-role-policy",
+					ValidationRule: "validation-lastAccessedRole",
+					Message:        "One or more required IAM permissions was not found, or a condition was not met",
+					Details:        []string{},
+					Failures: []string{
+						"v1alpha1.IamRoleRule lastAccessedRole missing action(s): [kms:DescribeKey s3:ListBuckets] for resource * from policy iamPolicy",
+						"v1alpha1.IamRoleRule lastAccessedRole required service kms has never been accessed",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+	}
+	for _, c := range cs {
+		result, err := lastAccessedService.ReconcileIAMRoleRule(c.rule)
+		util.CheckTestCase(t, result, c.expectedResult, err, c.expectedError)
+	}
+}
+
+func TestIAMRoleLastAccessedPending(t *testing.T) {
+	arn := "arn:aws:iam::123456789012:role/pendingRole"
+	rule := v1alpha1.IamRoleRule{
+		IamRoleName: "pendingRole",
+		Policies: []v1alpha1.PolicyDocument{
+			{
+				Name:    "iamPolicy",
+				Version: "1",
+				Statements: []v1alpha1.StatementEntry{
+					{
+						Effect:    "Allow",
+						Actions:   []string{"ec2:DescribeInstances"},
+						Resources: []string{"*"},
+					},
+				},
+			},
+		},
+		LastAccessed: &v1alpha1.LastAccessedCheck{UnusedDays: 90, Fail: true},
+	}
+	expectedResult := types.ValidationRuleResult{
+		Condition: &vapi.ValidationCondition{
+			ValidationType: "aws-iam-role-policy",
+			ValidationRule: "validation-pendingRole",
+			Message:        "All required aws-iam-role-policy permissions were found",
+			Details: []string{
+				"v1alpha1.IamRoleRule pendingRole service last accessed details are still being generated and will be checked on the next reconcile",
+			},
+			Failures: nil,
+			Status:   corev1.ConditionTrue,
+		},
+		State: util.Ptr(vapi.ValidationSucceeded),
+	}
+
+	// The in-progress job is kept for the next reconcile rather than waited on
+	result, err := lastAccessedService.ReconcileIAMRoleRule(rule)
+	util.CheckTestCase(t, result, expectedResult, err, nil)
+	if jobId := lastAccessedJobs.ids[arn]; jobId == nil || *jobId != arn {
+		t.Errorf("expected in-progress job %s to be kept, got %v", arn, jobId)
+	}
+
+	// Once the job completes, its results are evaluated and it's forgotten
+	lastAccessedJobs.ids[arn] = util.Ptr("arn:aws:iam::123456789012:role/lastAccessedRole")
+	result, err = lastAccessedService.ReconcileIAMRoleRule(rule)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Condition.Failures) != 2 {
+		t.Errorf("expected the completed job's unused services to be reported, got %v", result.Condition.Failures)
+	}
+	if _, ok := lastAccessedJobs.ids[arn]; ok {
+		t.Errorf("expected completed job for %s to be forgotten", arn)
+	}
+}
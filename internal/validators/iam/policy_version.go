@@ -0,0 +1,162 @@
+package iam
+
+import (
+	"context"
+	"fmt"
+
+	awspolicy "github.com/L30Bola/aws-policy"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamtypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"golang.org/x/exp/slices"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-aws/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-aws/internal/constants"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/types"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+type policyVersionRule interface {
+	TargetPolicyVersion() string
+}
+
+// reconcilePolicyVersion validates a specific version of an IAM policy, then reports whether the policy's
+// default version satisfies the rule and which required actions promoting the target version would grant or revoke
+func (s *IAMRuleService) reconcilePolicyVersion(rule iamRule, targetVersion string, vr *types.ValidationRuleResult) (*types.ValidationRuleResult, error) {
+	policyArn := rule.Name()
+	ctx := []string{"policy", policyArn}
+
+	policyOutput, err := s.iamSvc.GetPolicy(context.Background(), &iam.GetPolicyInput{
+		PolicyArn: util.Ptr(policyArn),
+	})
+	if err != nil {
+		s.log.V(0).Error(err, "failed to get IAM policy", "policyArn", policyArn)
+		return vr, err
+	}
+	defaultVersionId := *policyOutput.Policy.DefaultVersionId
+
+	versionId := targetVersion
+	if targetVersion == v1alpha1.PolicyVersionLatestNonDefault {
+		versionId, err = s.latestNonDefaultPolicyVersion(policyArn)
+		if err != nil {
+			return vr, err
+		}
+		if versionId == "" {
+			vr.State = util.Ptr(vapi.ValidationFailed)
+			vr.Condition.Failures = append(vr.Condition.Failures, fmt.Sprintf("IAM policy %s has no non-default version", policyArn))
+			vr.Condition.Message = "No non-default IAM policy version was found"
+			vr.Condition.Status = corev1.ConditionFalse
+			return vr, nil
+		}
+	}
+
+	// Validate the target version
+	targetDocument, err := s.getPolicyVersionDocument(util.Ptr(policyArn), util.Ptr(versionId), ctx)
+	if err != nil {
+		return vr, err
+	}
+	targetPermissions := evaluatePolicyVersion(rule, targetDocument)
+	computeFailures(rule, targetPermissions, vr)
+	computeExcessPermissions(rule, []*awspolicy.Policy{targetDocument}, vr)
+
+	vr.Condition.Details = append(vr.Condition.Details, fmt.Sprintf(
+		"Validated IAM policy version %s (default version %s)", versionId, defaultVersionId,
+	))
+	if versionId == defaultVersionId {
+		return vr, nil
+	}
+
+	// Validate the default version & compare it against the target version
+	defaultDocument, err := s.getPolicyVersionDocument(util.Ptr(policyArn), util.Ptr(defaultVersionId), ctx)
+	if err != nil {
+		return vr, err
+	}
+	defaultPermissions := evaluatePolicyVersion(rule, defaultDocument)
+	defaultVr := buildValidationResult(rule, constants.ValidationTypeIAMPolicy)
+	computeFailures(rule, defaultPermissions, defaultVr)
+	if len(defaultVr.Condition.Failures) == 0 {
+		vr.Condition.Details = append(vr.Condition.Details, fmt.Sprintf("Default version %s satisfies the rule", defaultVersionId))
+	} else {
+		vr.Condition.Details = append(vr.Condition.Details, fmt.Sprintf(
+			"Default version %s does not satisfy the rule: %d failure(s)", defaultVersionId, len(defaultVr.Condition.Failures),
+		))
+	}
+
+	defaultGranted := grantedRequiredActions(defaultPermissions)
+	targetGranted := grantedRequiredActions(targetPermissions)
+	added, removed := make([]string, 0), make([]string, 0)
+	for a := range targetGranted {
+		if !defaultGranted[a] {
+			added = append(added, a)
+		}
+	}
+	for a := range defaultGranted {
+		if !targetGranted[a] {
+			removed = append(removed, a)
+		}
+	}
+	slices.Sort(added)
+	slices.Sort(removed)
+	if len(added) > 0 {
+		vr.Condition.Details = append(vr.Condition.Details, fmt.Sprintf(
+			"Promoting version %s would grant required action(s): %s", versionId, added,
+		))
+	}
+	if len(removed) > 0 {
+		vr.Condition.Details = append(vr.Condition.Details, fmt.Sprintf(
+			"Promoting version %s would revoke required action(s): %s", versionId, removed,
+		))
+	}
+
+	return vr, nil
+}
+
+// latestNonDefaultPolicyVersion returns the ID of the most recently created non-default version of an IAM policy, if any
+func (s *IAMRuleService) latestNonDefaultPolicyVersion(policyArn string) (string, error) {
+	output, err := s.iamSvc.ListPolicyVersions(context.Background(), &iam.ListPolicyVersionsInput{
+		PolicyArn: util.Ptr(policyArn),
+	})
+	if err != nil {
+		s.log.V(0).Error(err, "failed to list IAM policy versions", "policyArn", policyArn)
+		return "", err
+	}
+
+	var latest *iamtypes.PolicyVersion
+	for i, v := range output.Versions {
+		if v.IsDefaultVersion || v.VersionId == nil || v.CreateDate == nil {
+			continue
+		}
+		if latest == nil || v.CreateDate.After(*latest.CreateDate) {
+			latest = &output.Versions[i]
+		}
+	}
+	if latest == nil {
+		return "", nil
+	}
+	return *latest.VersionId, nil
+}
+
+// evaluatePolicyVersion builds the permission map for an IAM rule and applies a policy document to it
+func evaluatePolicyVersion(rule iamRule, policyDocument *awspolicy.Policy) map[string][]*permission {
+	permissions := buildPermissions(rule)
+	if policyDocument != nil {
+		applyPolicy(policyDocument, permissions)
+	}
+	return permissions
+}
+
+// grantedRequiredActions returns the set of required actions, qualified by resource, that are allowed in a permission map
+func grantedRequiredActions(permissions map[string][]*permission) map[string]bool {
+	granted := make(map[string]bool)
+	for resource, resourcePermissions := range permissions {
+		for _, p := range resourcePermissions {
+			for action, allowed := range p.Actions {
+				if allowed {
+					granted[fmt.Sprintf("%s on %s", action.String(), resource)] = true
+				}
+			}
+		}
+	}
+	return granted
+}
@@ -0,0 +1,182 @@
+package iam
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamtypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-aws/api/v1alpha1"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/types"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+const (
+	versionedPolicyArn   string = "arn:aws:iam::123456789012:policy/versionedPolicy"
+	unversionedPolicyArn string = "arn:aws:iam::123456789012:policy/unversionedPolicy"
+
+	policyVersionDocument1 string = `{
+		"Version": "2012-10-17",
+		"Statement": [
+			{
+				"Effect": "Allow",
+				"Action": ["ec2:DescribeInstances"],
+				"Resource": ["*"]
+			}
+		]
+	}`
+	policyVersionDocument2 string = `{
+		"Version": "2012-10-17",
+		"Statement": [
+			{
+				"Effect": "Allow",
+				"Action": ["ec2:DescribeInstances", "s3:GetObject"],
+				"Resource": ["*"]
+			}
+		]
+	}`
+	policyVersionDocument3 string = `{
+		"Version": "2012-10-17",
+		"Statement": [
+			{
+				"Effect": "Allow",
+				"Action": ["s3:GetObject"],
+				"Resource": ["*"]
+			}
+		]
+	}`
+)
+
+var (
+	policyVersion2Created = time.Now().AddDate(0, 0, -2)
+	policyVersion3Created = time.Now().AddDate(0, 0, -1)
+)
+
+var policyVersionService = NewIAMRuleService(logr.Logger{}, iamApiMock{
+	policyArns: map[string]*iam.GetPolicyOutput{
+		versionedPolicyArn: {
+			Policy: util.Ptr(iamtypes.Policy{DefaultVersionId: util.Ptr("v1")}),
+		},
+		unversionedPolicyArn: {
+			Policy: util.Ptr(iamtypes.Policy{DefaultVersionId: util.Ptr("v1")}),
+		},
+	},
+	policyVersionsById: map[string]map[string]*iam.GetPolicyVersionOutput{
+		versionedPolicyArn: {
+			"v1": {PolicyVersion: util.Ptr(iamtypes.PolicyVersion{Document: util.Ptr(url.QueryEscape(policyVersionDocument1))})},
+			"v2": {PolicyVersion: util.Ptr(iamtypes.PolicyVersion{Document: util.Ptr(url.QueryEscape(policyVersionDocument2))})},
+			"v3": {PolicyVersion: util.Ptr(iamtypes.PolicyVersion{Document: util.Ptr(url.QueryEscape(policyVersionDocument3))})},
+		},
+	},
+	listPolicyVersions: map[string]*iam.ListPolicyVersionsOutput{
+		versionedPolicyArn: {
+			Versions: []iamtypes.PolicyVersion{
+				{VersionId: util.Ptr("v1"), IsDefaultVersion: true, CreateDate: util.Ptr(time.Now().AddDate(0, 0, -3))},
+				{VersionId: util.Ptr("v3"), CreateDate: &policyVersion3Created},
+				{VersionId: util.Ptr("v2"), CreateDate: &policyVersion2Created},
+			},
+		},
+		unversionedPolicyArn: {
+			Versions: []iamtypes.PolicyVersion{
+				{VersionId: util.Ptr("v1"), IsDefaultVersion: true, CreateDate: util.Ptr(time.Now().AddDate(0, 0, -3))},
+			},
+		},
+	},
+}, nil, nil, nil)
+
+func TestIAMPolicyVersionValidation(t *testing.T) {
+	policies := []v1alpha1.PolicyDocument{
+		{
+			Name:    "iamPolicy",
+			Version: "1",
+			Statements: []v1alpha1.StatementEntry{
+				{
+					Effect:    "Allow",
+					Actions:   []string{"ec2:DescribeInstances", "s3:GetObject"},
+					Resources: []string{"*"},
+				},
+			},
+		},
+	}
+	cs := []testCase{
+		{
+			name: "Pass (specific version)",
+			rule: v1alpha1.IamPolicyRule{
+				IamPolicyARN:  versionedPolicyArn,
+				Policies:      policies,
+				PolicyVersion: "v2",
+			},
+			expectedResult: types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "aws-iam-policy",
+					ValidationRule: "validation-" + versionedPolicyArn,
+					Message:        "All required aws-iam-policy permissions were found",
+					Details: []string{
+						"Validated IAM policy version v2 (default version v1)",
+						"Default version v1 does not satisfy the rule: 1 failure(s)",
+						"Promoting version v2 would grant required action(s): [s3:GetObject on *]",
+					},
+					Failures: nil,
+					Status:   corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Fail (latest non-default version)",
+			rule: v1alpha1.IamPolicyRule{
+				IamPolicyARN:  versionedPolicyArn,
+				Policies:      policies,
+				PolicyVersion: v1alpha1.PolicyVersionLatestNonDefault,
+			},
+			expectedResult: types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "aws-iam-policy",
+					ValidationRule: "validation-" + versionedPolicyArn,
+					Message:        "One or more required IAM permissions was not found, or a condition was not met",
+					Details: []string{
+						"Validated IAM policy version v3 (default version v1)",
+						"Default version v1 does not satisfy the rule: 1 failure(s)",
+						"Promoting version v3 would grant required action(s): [s3:GetObject on *]",
+						"Promoting version v3 would revoke required action(s): [ec2:DescribeInstances on *]",
+					},
+					Failures: []string{
+						"v1alpha1.IamPolicyRule " + versionedPolicyArn + " missing action(s): [ec2:DescribeInstances] for resource * from policy iamPolicy",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Fail (no non-default version)",
+			rule: v1alpha1.IamPolicyRule{
+				IamPolicyARN:  unversionedPolicyArn,
+				Policies:      policies,
+				PolicyVersion: v1alpha1.PolicyVersionLatestNonDefault,
+			},
+			expectedResult: types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "aws-iam-policy",
+					ValidationRule: "validation-" + unversionedPolicyArn,
+					Message:        "No non-default IAM policy version was found",
+					Details:        []string{},
+					Failures: []string{
+						"IAM policy " + unversionedPolicyArn + " has no non-default version",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+	}
+	for _, c := range cs {
+		result, err := policyVersionService.ReconcileIAMPolicyRule(c.rule)
+		util.CheckTestCase(t, result, c.expectedResult, err, c.expectedError)
+	}
+}
@@ -0,0 +1,245 @@
+package iam
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode"
+
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-aws/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-aws/internal/constants"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
+	"github.com/spectrocloud-labs/validator/pkg/types"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+// ReconcileIAMQuotaRule reconciles an IAM quota headroom validation rule from an AWSValidator config
+func (s *IAMRuleService) ReconcileIAMQuotaRule(rule v1alpha1.IamQuotaRule) (*types.ValidationRuleResult, error) {
+
+	// Build the default latest condition for this IAM rule
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Message = "Usage for all IAM quotas is below specified buffer"
+	latestCondition.ValidationRule = fmt.Sprintf("%s-%s", vapiconstants.ValidationRulePrefix, rule.Name)
+	latestCondition.ValidationType = constants.ValidationTypeIAMQuota
+	vr := &types.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	failures := make([]string, 0)
+	notFound := 0
+
+	summary, err := s.iamSvc.GetAccountSummary(context.Background(), &iam.GetAccountSummaryInput{})
+	if err != nil {
+		s.log.V(0).Error(err, "failed to get account summary")
+		return vr, err
+	}
+
+	// Account-wide quotas
+	for _, q := range rule.AccountQuotas {
+		quotaName := fmt.Sprintf("%sQuota", q.Name)
+		quota, ok := summary.SummaryMap[quotaName]
+		if !ok {
+			failures = append(failures, quotaNotFound(quotaName))
+			notFound++
+			continue
+		}
+		usage := summary.SummaryMap[q.Name]
+		failures = appendQuotaFailure(failures, int(quota), int(usage), q.Buffer, q.Name)
+		latestCondition.Details = append(latestCondition.Details, fmt.Sprintf(
+			"%s: quota: %d, buffer: %d, usage: %d", q.Name, quota, q.Buffer, usage,
+		))
+	}
+
+	// Per-principal policy attachment quotas
+	for _, p := range rule.Principals {
+		attached, err := s.attachedPolicyCount(p)
+		if err != nil {
+			return vr, err
+		}
+		quotaName := fmt.Sprintf("AttachedPoliciesPer%sQuota", p.Type)
+		if quota, ok := summary.SummaryMap[quotaName]; ok {
+			description := fmt.Sprintf("attached managed policies for IAM %s %s", strings.ToLower(p.Type), p.Name)
+			failures = appendQuotaFailure(failures, int(quota), attached, p.AttachedPoliciesBuffer, description)
+			latestCondition.Details = append(latestCondition.Details, fmt.Sprintf(
+				"%s: quota: %d, buffer: %d, usage: %d", description, quota, p.AttachedPoliciesBuffer, attached,
+			))
+		} else {
+			failures = append(failures, quotaNotFound(quotaName))
+			notFound++
+		}
+
+		if p.InlinePolicySizeBuffer == 0 {
+			continue
+		}
+		size, err := s.inlinePolicySize(p)
+		if err != nil {
+			return vr, err
+		}
+		quota := inlinePolicySizeQuota(p.Type)
+		description := fmt.Sprintf("inline policy size for IAM %s %s", strings.ToLower(p.Type), p.Name)
+		failures = appendQuotaFailure(failures, quota, size, p.InlinePolicySizeBuffer, description)
+		latestCondition.Details = append(latestCondition.Details, fmt.Sprintf(
+			"%s: quota: %d, buffer: %d, usage: %d", description, quota, p.InlinePolicySizeBuffer, size,
+		))
+	}
+
+	if len(failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Failures = failures
+		latestCondition.Message = "Usage for one or more IAM quotas exceeded the specified buffer"
+		if notFound == len(failures) {
+			latestCondition.Message = "One or more IAM quotas were not found"
+		}
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return vr, nil
+}
+
+// appendQuotaFailure appends a failure if the remaining quota is less than the buffer
+func appendQuotaFailure(failures []string, quota, usage, buffer int, description string) []string {
+	remainder := quota - usage
+	if remainder < buffer {
+		failures = append(failures, fmt.Sprintf(
+			"Remaining quota %d, less than buffer %d, for IAM quota %s", remainder, buffer, description,
+		))
+	}
+	return failures
+}
+
+// quotaNotFound returns a failure for an IAM quota that isn't reported by GetAccountSummary, e.g., a misspelled name
+func quotaNotFound(quotaName string) string {
+	return fmt.Sprintf("IAM quota %s not found in account summary", quotaName)
+}
+
+// attachedPolicyCount determines the number of managed policies attached to an IAM principal
+func (s *IAMRuleService) attachedPolicyCount(p v1alpha1.IamPrincipalQuota) (int, error) {
+	switch p.Type {
+	case "Role":
+		output, err := s.iamSvc.ListAttachedRolePolicies(context.Background(), &iam.ListAttachedRolePoliciesInput{
+			RoleName: util.Ptr(p.Name),
+		})
+		if err != nil {
+			s.log.V(0).Error(err, "failed to list policies for IAM role", "role", p.Name)
+			return 0, err
+		}
+		return len(output.AttachedPolicies), nil
+	case "User":
+		output, err := s.iamSvc.ListAttachedUserPolicies(context.Background(), &iam.ListAttachedUserPoliciesInput{
+			UserName: util.Ptr(p.Name),
+		})
+		if err != nil {
+			s.log.V(0).Error(err, "failed to list policies for IAM user", "name", p.Name)
+			return 0, err
+		}
+		return len(output.AttachedPolicies), nil
+	case "Group":
+		output, err := s.iamSvc.ListAttachedGroupPolicies(context.Background(), &iam.ListAttachedGroupPoliciesInput{
+			GroupName: util.Ptr(p.Name),
+		})
+		if err != nil {
+			s.log.V(0).Error(err, "failed to list policies for IAM group", "name", p.Name)
+			return 0, err
+		}
+		return len(output.AttachedPolicies), nil
+	default:
+		return 0, fmt.Errorf("invalid IAM principal type: %s", p.Type)
+	}
+}
+
+// inlinePolicySize determines the aggregate size of an IAM principal's inline policies.
+// Per AWS, whitespace is not counted towards the size quota.
+func (s *IAMRuleService) inlinePolicySize(p v1alpha1.IamPrincipalQuota) (int, error) {
+	documents := make([]string, 0)
+	switch p.Type {
+	case "Role":
+		output, err := s.iamSvc.ListRolePolicies(context.Background(), &iam.ListRolePoliciesInput{
+			RoleName: util.Ptr(p.Name),
+		})
+		if err != nil {
+			s.log.V(0).Error(err, "failed to list inline policies for IAM role", "role", p.Name)
+			return 0, err
+		}
+		for _, policyName := range output.PolicyNames {
+			policy, err := s.iamSvc.GetRolePolicy(context.Background(), &iam.GetRolePolicyInput{
+				PolicyName: util.Ptr(policyName),
+				RoleName:   util.Ptr(p.Name),
+			})
+			if err != nil {
+				s.log.V(0).Error(err, "failed to get inline policy for IAM role", "role", p.Name, "policyName", policyName)
+				return 0, err
+			}
+			documents = append(documents, *policy.PolicyDocument)
+		}
+	case "User":
+		output, err := s.iamSvc.ListUserPolicies(context.Background(), &iam.ListUserPoliciesInput{
+			UserName: util.Ptr(p.Name),
+		})
+		if err != nil {
+			s.log.V(0).Error(err, "failed to list inline policies for IAM user", "name", p.Name)
+			return 0, err
+		}
+		for _, policyName := range output.PolicyNames {
+			policy, err := s.iamSvc.GetUserPolicy(context.Background(), &iam.GetUserPolicyInput{
+				PolicyName: util.Ptr(policyName),
+				UserName:   util.Ptr(p.Name),
+			})
+			if err != nil {
+				s.log.V(0).Error(err, "failed to get inline policy for IAM user", "name", p.Name, "policyName", policyName)
+				return 0, err
+			}
+			documents = append(documents, *policy.PolicyDocument)
+		}
+	case "Group":
+		output, err := s.iamSvc.ListGroupPolicies(context.Background(), &iam.ListGroupPoliciesInput{
+			GroupName: util.Ptr(p.Name),
+		})
+		if err != nil {
+			s.log.V(0).Error(err, "failed to list inline policies for IAM group", "name", p.Name)
+			return 0, err
+		}
+		for _, policyName := range output.PolicyNames {
+			policy, err := s.iamSvc.GetGroupPolicy(context.Background(), &iam.GetGroupPolicyInput{
+				GroupName:  util.Ptr(p.Name),
+				PolicyName: util.Ptr(policyName),
+			})
+			if err != nil {
+				s.log.V(0).Error(err, "failed to get inline policy for IAM group", "name", p.Name, "policyName", policyName)
+				return 0, err
+			}
+			documents = append(documents, *policy.PolicyDocument)
+		}
+	default:
+		return 0, fmt.Errorf("invalid IAM principal type: %s", p.Type)
+	}
+
+	var size int
+	for _, d := range documents {
+		document, err := url.QueryUnescape(d)
+		if err != nil {
+			return 0, err
+		}
+		for _, r := range document {
+			if !unicode.IsSpace(r) {
+				size++
+			}
+		}
+	}
+	return size, nil
+}
+
+// inlinePolicySizeQuota returns the aggregate inline policy size quota for an IAM principal type
+func inlinePolicySizeQuota(principalType string) int {
+	switch principalType {
+	case "Role":
+		return constants.InlinePolicySizeQuotaRole
+	case "User":
+		return constants.InlinePolicySizeQuotaUser
+	default:
+		return constants.InlinePolicySizeQuotaGroup
+	}
+}
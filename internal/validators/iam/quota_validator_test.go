@@ -0,0 +1,150 @@
+package iam
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamtypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-aws/api/v1alpha1"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/types"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+var quotaService = NewIAMRuleService(logr.Logger{}, iamApiMock{
+	accountSummary: &iam.GetAccountSummaryOutput{
+		SummaryMap: map[string]int32{
+			"AttachedPoliciesPerRoleQuota": 10,
+			"AttachedPoliciesPerUserQuota": 10,
+			"Policies":                     10,
+			"PoliciesQuota":                1500,
+			"Roles":                        995,
+			"RolesQuota":                   1000,
+		},
+	},
+	attachedRolePolicies: map[string]*iam.ListAttachedRolePoliciesOutput{
+		"quotaRole": {
+			AttachedPolicies: []iamtypes.AttachedPolicy{
+				{PolicyName: util.Ptr("iamPolicy1")},
+				{PolicyName: util.Ptr("iamPolicy2")},
+			},
+		},
+	},
+	attachedGroupPolicies: map[string]*iam.ListAttachedGroupPoliciesOutput{
+		"quotaGroup": {},
+	},
+	attachedUserPolicies: map[string]*iam.ListAttachedUserPoliciesOutput{
+		"quotaUser": {
+			AttachedPolicies: []iamtypes.AttachedPolicy{
+				{PolicyName: util.Ptr("iamPolicy1")},
+			},
+		},
+	},
+	inlinePolicies: map[string]map[string]string{
+		"quotaUser": {
+			"inlinePolicy": url.QueryEscape(`{ "Version": "2012-10-17" }`),
+		},
+	},
+}, nil, nil, nil)
+
+type quotaTestCase struct {
+	name           string
+	rule           v1alpha1.IamQuotaRule
+	expectedResult types.ValidationRuleResult
+	expectedError  error
+}
+
+func TestIAMQuotaValidation(t *testing.T) {
+	cs := []quotaTestCase{
+		{
+			name: "Pass (basic)",
+			rule: v1alpha1.IamQuotaRule{
+				Name: "headroom",
+				AccountQuotas: []v1alpha1.IamAccountQuota{
+					{Name: "Policies", Buffer: 10},
+				},
+				Principals: []v1alpha1.IamPrincipalQuota{
+					{Type: "User", Name: "quotaUser", AttachedPoliciesBuffer: 5, InlinePolicySizeBuffer: 1000},
+				},
+			},
+			expectedResult: types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "aws-iam-quota",
+					ValidationRule: "validation-headroom",
+					Message:        "Usage for all IAM quotas is below specified buffer",
+					Details: []string{
+						"Policies: quota: 1500, buffer: 10, usage: 10",
+						"attached managed policies for IAM user quotaUser: quota: 10, buffer: 5, usage: 1",
+						"inline policy size for IAM user quotaUser: quota: 2048, buffer: 1000, usage: 24",
+					},
+					Failures: nil,
+					Status:   corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Fail (insufficient headroom)",
+			rule: v1alpha1.IamQuotaRule{
+				Name: "headroom",
+				AccountQuotas: []v1alpha1.IamAccountQuota{
+					{Name: "Roles", Buffer: 10},
+				},
+				Principals: []v1alpha1.IamPrincipalQuota{
+					{Type: "Role", Name: "quotaRole", AttachedPoliciesBuffer: 9},
+				},
+			},
+			expectedResult: types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "aws-iam-quota",
+					ValidationRule: "validation-headroom",
+					Message:        "Usage for one or more IAM quotas exceeded the specified buffer",
+					Details: []string{
+						"Roles: quota: 1000, buffer: 10, usage: 995",
+						"attached managed policies for IAM role quotaRole: quota: 10, buffer: 9, usage: 2",
+					},
+					Failures: []string{
+						"Remaining quota 5, less than buffer 10, for IAM quota Roles",
+						"Remaining quota 8, less than buffer 9, for IAM quota attached managed policies for IAM role quotaRole",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Fail (quota not found)",
+			rule: v1alpha1.IamQuotaRule{
+				Name: "headroom",
+				AccountQuotas: []v1alpha1.IamAccountQuota{
+					{Name: "Polices", Buffer: 10},
+				},
+				Principals: []v1alpha1.IamPrincipalQuota{
+					{Type: "Group", Name: "quotaGroup", AttachedPoliciesBuffer: 5},
+				},
+			},
+			expectedResult: types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "aws-iam-quota",
+					ValidationRule: "validation-headroom",
+					Message:        "One or more IAM quotas were not found",
+					Details:        []string{},
+					Failures: []string{
+						"IAM quota PolicesQuota not found in account summary",
+						"IAM quota AttachedPoliciesPerGroupQuota not found in account summary",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+	}
+	for _, c := range cs {
+		result, err := quotaService.ReconcileIAMQuotaRule(c.rule)
+		util.CheckTestCase(t, result, c.expectedResult, err, c.expectedError)
+	}
+}
@@ -0,0 +1,405 @@
+package iam
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	awspolicy "github.com/L30Bola/aws-policy"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/aws/smithy-go"
+	"golang.org/x/exp/slices"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-aws/internal/constants"
+	aws_utils "github.com/spectrocloud-labs/validator-plugin-aws/internal/utils/aws"
+	str_utils "github.com/spectrocloud-labs/validator-plugin-aws/internal/utils/strings"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/types"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+type resourcePolicyRule interface {
+	ResourcePoliciesEnabled() bool
+}
+
+// s3Api is the subset of the S3 API used to locate buckets and fetch their bucket policies
+type s3Api interface {
+	GetBucketLocation(ctx context.Context, params *s3.GetBucketLocationInput, optFns ...func(*s3.Options)) (*s3.GetBucketLocationOutput, error)
+	GetBucketPolicy(ctx context.Context, params *s3.GetBucketPolicyInput, optFns ...func(*s3.Options)) (*s3.GetBucketPolicyOutput, error)
+}
+
+// kmsApi is the subset of the KMS API used to fetch key policies
+type kmsApi interface {
+	GetKeyPolicy(ctx context.Context, params *kms.GetKeyPolicyInput, optFns ...func(*kms.Options)) (*kms.GetKeyPolicyOutput, error)
+}
+
+// sqsApi is the subset of the SQS API used to resolve queue URLs and fetch queue policies
+type sqsApi interface {
+	GetQueueUrl(ctx context.Context, params *sqs.GetQueueUrlInput, optFns ...func(*sqs.Options)) (*sqs.GetQueueUrlOutput, error)
+	GetQueueAttributes(ctx context.Context, params *sqs.GetQueueAttributesInput, optFns ...func(*sqs.Options)) (*sqs.GetQueueAttributesOutput, error)
+}
+
+// resourcePolicy is the resource-based policy of an S3 bucket, KMS key, or SQS queue
+type resourcePolicy struct {
+	// Kind describes the policy in failure messages, e.g., KMS key policy
+	Kind string
+	// Account is the ID of the AWS account that owns the resource, if known
+	Account string
+	// Authoritative is true if the policy must grant access to the principal or its account
+	// for the principal's IAM policies to take effect, i.e., for KMS key policies
+	Authoritative bool
+	Document      *awspolicy.Policy
+}
+
+// resourceArn is a parsed ARN of an S3 bucket or object, KMS key, or SQS queue
+type resourceArn struct {
+	Arn      string
+	Service  string
+	Region   string
+	Account  string
+	Resource string
+}
+
+// checkResourcePolicies evaluates the resource-based policies of the S3 buckets, KMS keys, and SQS queues in an IAM rule's
+// resources for an IAM principal. Required actions granted to the principal by a resource policy are marked as allowed in the
+// permission map. Failures are returned for required actions that a resource policy denies or does not grant to the principal.
+func (s *IAMRuleService) checkResourcePolicies(rule iamRule, principalArn string, permissions map[string][]*permission, vr *types.ValidationRuleResult) ([]string, error) {
+	rr, ok := rule.(resourcePolicyRule)
+	if !ok || !rr.ResourcePoliciesEnabled() {
+		return nil, nil
+	}
+
+	resources := make([]string, 0, len(permissions))
+	for r := range permissions {
+		resources = append(resources, r)
+	}
+	sort.Strings(resources)
+
+	principal, err := aws_utils.ParseARN(principalArn)
+	if err != nil {
+		return nil, err
+	}
+	rootArn := fmt.Sprintf("arn:%s:iam::%s:root", principal.Partition, principal.AccountID)
+
+	failures := make([]string, 0)
+	details := make([]string, 0)
+	policies := make(map[string]*resourcePolicy)
+	for _, resource := range resources {
+		arn, ok := parseResourceArn(resource)
+		if !ok {
+			continue
+		}
+		policy, err := s.getResourcePolicy(arn, policies)
+		if err != nil {
+			s.log.V(0).Error(err, "failed to get resource policy", "name", rule.Name(), "resource", resource)
+			return nil, err
+		} else if policy == nil {
+			continue
+		}
+		vr.Condition.Details = append(vr.Condition.Details, fmt.Sprintf("Evaluated %s for resource %s", policy.Kind, resource))
+
+		crossAccount := policy.Account != "" && policy.Account != principal.AccountID
+		for _, p := range permissions[resource] {
+			actions := make([]iamAction, 0, len(p.Actions))
+			for a := range p.Actions {
+				actions = append(actions, a)
+			}
+			sort.Slice(actions, func(i, j int) bool { return actions[i].String() < actions[j].String() })
+
+			for _, action := range actions {
+				identityAllowed := p.Actions[action]
+				denied, mayBeDenied, direct, delegated := evaluateResourcePolicy(policy.Document, action, resource, principalArn, principal.AccountID, rootArn)
+
+				var allowed bool
+				switch {
+				case policy.Authoritative && crossAccount:
+					allowed = identityAllowed && (direct || delegated)
+				case policy.Authoritative:
+					allowed = direct || (delegated && identityAllowed)
+				case crossAccount:
+					allowed = identityAllowed && (direct || delegated)
+				default:
+					allowed = identityAllowed || direct
+				}
+
+				switch {
+				case denied && (identityAllowed || allowed):
+					failures = append(failures, fmt.Sprintf(
+						"Action %s for resource %s is denied by the %s", action.String(), resource, policy.Kind,
+					))
+				case !allowed && identityAllowed:
+					failures = append(failures, fmt.Sprintf(
+						"Action %s for resource %s is not granted to %s by the %s", action.String(), resource, principalArn, policy.Kind,
+					))
+				case allowed && !identityAllowed:
+					// fold the resource policy's grant into the permission map
+					p.Actions[action] = true
+				}
+				if mayBeDenied && !denied && (identityAllowed || allowed) {
+					details = append(details, fmt.Sprintf(
+						"Action %s for resource %s may be denied by a statement in the %s with a Condition, NotPrincipal, NotAction, or NotResource element",
+						action.String(), resource, policy.Kind,
+					))
+				}
+			}
+		}
+	}
+	vr.Condition.Details = append(vr.Condition.Details, str_utils.DeDupeStrSlice(details)...)
+	return str_utils.DeDupeStrSlice(failures), nil
+}
+
+// applyResourcePolicyFailures updates a ValidationRuleResult with resource policy failures
+func applyResourcePolicyFailures(vr *types.ValidationRuleResult, failures []string) {
+	if len(failures) == 0 {
+		return
+	}
+	vr.State = util.Ptr(vapi.ValidationFailed)
+	vr.Condition.Failures = append(vr.Condition.Failures, failures...)
+	vr.Condition.Message = "One or more required IAM permissions was not found, or a condition was not met"
+	vr.Condition.Status = corev1.ConditionFalse
+}
+
+// evaluateResourcePolicy determines whether a resource policy explicitly denies a required action for a resource to a principal,
+// grants it to the principal directly, or grants it to the principal's account, delegating access to the principal's IAM policies.
+// Statements with conditions, NotPrincipal, NotAction, or NotResource elements are not fully evaluated: they never grant access,
+// and Deny statements among them that may apply to the principal, action, and resource are reported via mayBeDenied.
+func evaluateResourcePolicy(policy *awspolicy.Policy, action iamAction, resource, principalArn, principalAccount, rootArn string) (denied, mayBeDenied, direct, delegated bool) {
+	for _, st := range policy.Statements {
+		if len(st.Condition) > 0 || len(st.NotPrincipal) > 0 || len(st.NotAction) > 0 || len(st.NotResource) > 0 {
+			if st.Effect == "Deny" && statementMayApply(st, action, resource, principalArn, principalAccount, rootArn) {
+				mayBeDenied = true
+			}
+			continue
+		}
+		if !coversAction(st.Action, action) || !coversResource(st.Resource, resource) {
+			continue
+		}
+		stDirect, stDelegated := false, false
+		for _, p := range st.Principal["AWS"] {
+			switch p {
+			case constants.IAMWildcard, principalArn:
+				stDirect = true
+			case principalAccount, rootArn:
+				stDelegated = true
+			}
+		}
+		switch st.Effect {
+		case "Deny":
+			denied = denied || stDirect || stDelegated
+		case "Allow":
+			direct = direct || stDirect
+			delegated = delegated || stDelegated
+		}
+	}
+	return denied, mayBeDenied, direct, delegated
+}
+
+// statementMayApply determines whether a statement applies to an action for a resource and principal, ignoring its conditions
+func statementMayApply(st awspolicy.Statement, action iamAction, resource, principalArn, principalAccount, rootArn string) bool {
+	if len(st.NotAction) > 0 {
+		if coversAction(st.NotAction, action) {
+			return false
+		}
+	} else if !coversAction(st.Action, action) {
+		return false
+	}
+	if len(st.NotResource) > 0 {
+		if coversResource(st.NotResource, resource) {
+			return false
+		}
+	} else if !coversResource(st.Resource, resource) {
+		return false
+	}
+
+	isPrincipal := func(p string) bool {
+		return p == constants.IAMWildcard || p == principalArn || p == principalAccount || p == rootArn
+	}
+	if len(st.NotPrincipal) > 0 {
+		return !slices.ContainsFunc(st.NotPrincipal["AWS"], isPrincipal)
+	}
+	return slices.ContainsFunc(st.Principal["AWS"], isPrincipal)
+}
+
+func coversAction(actions []string, action iamAction) bool {
+	for _, a := range actions {
+		granted := toIAMAction(a)
+		if granted.Covers(action) {
+			return true
+		}
+	}
+	return false
+}
+
+func coversResource(resources []string, resource string) bool {
+	for _, r := range resources {
+		if r == constants.IAMWildcard || str_utils.MatchWildcard(r, resource) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseResourceArn parses the ARN of an S3 bucket or object, KMS key, or SQS queue.
+// False is returned for other resources and for ARNs that do not identify a single resource policy.
+func parseResourceArn(resource string) (resourceArn, bool) {
+	parsed, err := aws_utils.ParseARN(resource)
+	if err != nil {
+		return resourceArn{}, false
+	}
+	arn := resourceArn{Arn: resource, Service: parsed.Service, Region: parsed.Region, Account: parsed.AccountID}
+	switch arn.Service {
+	case "s3":
+		arn.Resource, _, _ = strings.Cut(parsed.Resource, "/")
+	case "kms":
+		key, ok := strings.CutPrefix(parsed.Resource, "key/")
+		if !ok {
+			return resourceArn{}, false
+		}
+		arn.Resource = key
+	case "sqs":
+		arn.Resource = parsed.Resource
+	default:
+		return resourceArn{}, false
+	}
+	if arn.Resource == "" || strings.ContainsAny(arn.Resource+arn.Region+arn.Account, "*?") {
+		return resourceArn{}, false
+	}
+	return arn, true
+}
+
+// getResourcePolicy fetches and parses the resource policy for a resource, if it has one. Policies are cached by resource.
+func (s *IAMRuleService) getResourcePolicy(arn resourceArn, cache map[string]*resourcePolicy) (*resourcePolicy, error) {
+	key := fmt.Sprintf("%s:%s:%s:%s", arn.Service, arn.Region, arn.Account, arn.Resource)
+	if policy, ok := cache[key]; ok {
+		return policy, nil
+	}
+
+	var document string
+	policy := &resourcePolicy{Account: arn.Account}
+	switch arn.Service {
+	case "s3":
+		if s.s3Svc == nil {
+			return nil, nil
+		}
+		policy.Kind = "S3 bucket policy"
+
+		location, err := s.s3Svc.GetBucketLocation(context.Background(), &s3.GetBucketLocationInput{
+			Bucket: util.Ptr(arn.Resource),
+		})
+		if err != nil {
+			return nil, err
+		}
+		region := string(location.LocationConstraint)
+		switch region {
+		case "":
+			region = "us-east-1"
+		case "EU":
+			region = "eu-west-1"
+		}
+
+		bucketPolicy, err := s.s3Svc.GetBucketPolicy(context.Background(), &s3.GetBucketPolicyInput{
+			Bucket: util.Ptr(arn.Resource),
+		}, func(o *s3.Options) { o.Region = region })
+		if err != nil {
+			var ae smithy.APIError
+			if errors.As(err, &ae) && ae.ErrorCode() == "NoSuchBucketPolicy" {
+				cache[key] = nil
+				return nil, nil
+			}
+			return nil, err
+		}
+		if bucketPolicy.Policy != nil {
+			document = *bucketPolicy.Policy
+		}
+	case "kms":
+		if s.kmsSvc == nil {
+			return nil, nil
+		}
+		policy.Kind = "KMS key policy"
+		policy.Authoritative = true
+
+		keyPolicy, err := s.kmsSvc.GetKeyPolicy(context.Background(), &kms.GetKeyPolicyInput{
+			KeyId:      util.Ptr(arn.Arn),
+			PolicyName: util.Ptr("default"),
+		}, func(o *kms.Options) { o.Region = arn.Region })
+		if err != nil {
+			return nil, err
+		}
+		if keyPolicy.Policy != nil {
+			document = *keyPolicy.Policy
+		}
+	case "sqs":
+		if s.sqsSvc == nil {
+			return nil, nil
+		}
+		policy.Kind = "SQS queue policy"
+
+		queueUrl, err := s.sqsSvc.GetQueueUrl(context.Background(), &sqs.GetQueueUrlInput{
+			QueueName:              util.Ptr(arn.Resource),
+			QueueOwnerAWSAccountId: util.Ptr(arn.Account),
+		}, func(o *sqs.Options) { o.Region = arn.Region })
+		if err != nil {
+			return nil, err
+		}
+		attributes, err := s.sqsSvc.GetQueueAttributes(context.Background(), &sqs.GetQueueAttributesInput{
+			QueueUrl:       queueUrl.QueueUrl,
+			AttributeNames: []sqstypes.QueueAttributeName{sqstypes.QueueAttributeNamePolicy},
+		}, func(o *sqs.Options) { o.Region = arn.Region })
+		if err != nil {
+			return nil, err
+		}
+		document = attributes.Attributes[string(sqstypes.QueueAttributeNamePolicy)]
+	}
+
+	if document == "" {
+		cache[key] = nil
+		return nil, nil
+	}
+	policyDocument, err := parseResourcePolicyDocument(document)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s for %s %s: %w", policy.Kind, arn.Service, arn.Resource, err)
+	}
+	policy.Document = policyDocument
+	cache[key] = policy
+	return policy, nil
+}
+
+// parseResourcePolicyDocument parses a resource policy document. Principal and NotPrincipal elements specified as
+// a single string, e.g., "Principal": "*", are normalized to their equivalent AWS principal map prior to parsing.
+func parseResourcePolicyDocument(document string) (*awspolicy.Policy, error) {
+	raw := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(document), &raw); err != nil {
+		return nil, err
+	}
+	statements, ok := raw["Statement"].([]interface{})
+	if !ok {
+		statements = []interface{}{raw["Statement"]}
+	}
+	for _, st := range statements {
+		statement, ok := st.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, k := range []string{"Principal", "NotPrincipal"} {
+			if p, ok := statement[k].(string); ok {
+				statement[k] = map[string]interface{}{"AWS": p}
+			}
+		}
+	}
+	normalized, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	policyDocument := &awspolicy.Policy{}
+	if err := policyDocument.UnmarshalJSON(normalized); err != nil {
+		return nil, err
+	}
+	return policyDocument, nil
+}
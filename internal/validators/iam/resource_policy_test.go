@@ -0,0 +1,373 @@
+package iam
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamtypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/smithy-go"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-aws/api/v1alpha1"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/types"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+const (
+	resourcePolicyRoleArn string = "arn:aws:iam::123456789012:role/resourcePolicyRole"
+	resourcePolicyKeyArn  string = "arn:aws:kms:us-west-2:123456789012:key/1234abcd-12ab-34cd-56ef-1234567890ab"
+	resourcePolicyQueue   string = "arn:aws:sqs:us-west-2:123456789012:resourcePolicyQueue"
+
+	resourcePolicyIdentityDocument string = `{
+		"Version": "2012-10-17",
+		"Statement": [
+			{
+				"Effect": "Allow",
+				"Action": ["kms:Decrypt", "s3:GetObject", "s3:PutObject"],
+				"Resource": ["*"]
+			}
+		]
+	}`
+	bucketPolicyDocument string = `{
+		"Version": "2012-10-17",
+		"Statement": [
+			{
+				"Effect": "Deny",
+				"Principal": "*",
+				"Action": "s3:PutObject",
+				"Resource": "arn:aws:s3:::resourcePolicyBucket/*"
+			},
+			{
+				"Effect": "Deny",
+				"Principal": "*",
+				"Action": "s3:*",
+				"Resource": "arn:aws:s3:::resourcePolicyBucket/*",
+				"Condition": {"Bool": {"aws:SecureTransport": "false"}}
+			}
+		]
+	}`
+	keyPolicyDocument string = `{
+		"Version": "2012-10-17",
+		"Statement": [
+			{
+				"Effect": "Allow",
+				"Principal": {"AWS": "arn:aws:iam::123456789012:role/keyAdmin"},
+				"Action": "kms:*",
+				"Resource": "*"
+			}
+		]
+	}`
+	queuePolicyDocument string = `{
+		"Version": "2012-10-17",
+		"Statement": [
+			{
+				"Effect": "Allow",
+				"Principal": {"AWS": ["arn:aws:iam::123456789012:role/resourcePolicyRole"]},
+				"Action": "sqs:SendMessage",
+				"Resource": "arn:aws:sqs:us-west-2:123456789012:resourcePolicyQueue"
+			}
+		]
+	}`
+)
+
+type s3ApiMock struct {
+	bucketPolicies map[string]string
+}
+
+func (m s3ApiMock) GetBucketLocation(ctx context.Context, params *s3.GetBucketLocationInput, optFns ...func(*s3.Options)) (*s3.GetBucketLocationOutput, error) {
+	return &s3.GetBucketLocationOutput{}, nil
+}
+
+func (m s3ApiMock) GetBucketPolicy(ctx context.Context, params *s3.GetBucketPolicyInput, optFns ...func(*s3.Options)) (*s3.GetBucketPolicyOutput, error) {
+	policy, ok := m.bucketPolicies[*params.Bucket]
+	if !ok {
+		return nil, &smithy.GenericAPIError{Code: "NoSuchBucketPolicy"}
+	}
+	return &s3.GetBucketPolicyOutput{Policy: util.Ptr(policy)}, nil
+}
+
+type kmsApiMock struct {
+	keyPolicies map[string]string
+}
+
+func (m kmsApiMock) GetKeyPolicy(ctx context.Context, params *kms.GetKeyPolicyInput, optFns ...func(*kms.Options)) (*kms.GetKeyPolicyOutput, error) {
+	return &kms.GetKeyPolicyOutput{Policy: util.Ptr(m.keyPolicies[*params.KeyId])}, nil
+}
+
+type sqsApiMock struct {
+	queuePolicies map[string]string
+}
+
+func (m sqsApiMock) GetQueueUrl(ctx context.Context, params *sqs.GetQueueUrlInput, optFns ...func(*sqs.Options)) (*sqs.GetQueueUrlOutput, error) {
+	return &sqs.GetQueueUrlOutput{QueueUrl: params.QueueName}, nil
+}
+
+func (m sqsApiMock) GetQueueAttributes(ctx context.Context, params *sqs.GetQueueAttributesInput, optFns ...func(*sqs.Options)) (*sqs.GetQueueAttributesOutput, error) {
+	return &sqs.GetQueueAttributesOutput{
+		Attributes: map[string]string{"Policy": m.queuePolicies[*params.QueueUrl]},
+	}, nil
+}
+
+var resourcePolicyService = NewIAMRuleService(logr.Logger{}, iamApiMock{
+	attachedRolePolicies: map[string]*iam.ListAttachedRolePoliciesOutput{
+		"resourcePolicyRole": {
+			AttachedPolicies: []iamtypes.AttachedPolicy{
+				{
+					PolicyArn:  util.Ptr("arn:aws:iam::123456789012:policy/resourcePolicyIdentity"),
+					PolicyName: util.Ptr("resourcePolicyIdentity"),
+				},
+			},
+		},
+	},
+	policyArns: map[string]*iam.GetPolicyOutput{
+		"arn:aws:iam::123456789012:policy/resourcePolicyIdentity": {
+			Policy: util.Ptr(iamtypes.Policy{
+				DefaultVersionId: util.Ptr("1"),
+			}),
+		},
+	},
+	policyVersions: map[string]*iam.GetPolicyVersionOutput{
+		"arn:aws:iam::123456789012:policy/resourcePolicyIdentity": {
+			PolicyVersion: util.Ptr(iamtypes.PolicyVersion{
+				Document: util.Ptr(url.QueryEscape(resourcePolicyIdentityDocument)),
+			}),
+		},
+	},
+	role: map[string]*iam.GetRoleOutput{
+		"resourcePolicyRole": {
+			Role: &iamtypes.Role{
+				Arn:      util.Ptr(resourcePolicyRoleArn),
+				RoleName: util.Ptr("resourcePolicyRole"),
+				RoleId:   util.Ptr("resourcePolicyRoleID"),
+			},
+		},
+	},
+	simulatePrincipalPolicyResult: map[string]*iam.SimulatePrincipalPolicyOutput{
+		resourcePolicyRoleArn: {},
+	},
+}, s3ApiMock{
+	bucketPolicies: map[string]string{
+		"resourcePolicyBucket": bucketPolicyDocument,
+	},
+}, kmsApiMock{
+	keyPolicies: map[string]string{
+		resourcePolicyKeyArn: keyPolicyDocument,
+	},
+}, sqsApiMock{
+	queuePolicies: map[string]string{
+		"resourcePolicyQueue": queuePolicyDocument,
+	},
+})
+
+func TestResourcePolicyValidation(t *testing.T) {
+	cs := []testCase{
+		{
+			name: "Pass (resource policies disabled)",
+			rule: v1alpha1.IamRoleRule{
+				IamRoleName: "resourcePolicyRole",
+				Policies: []v1alpha1.PolicyDocument{
+					{
+						Name:    "iamPolicy",
+						Version: "1",
+						Statements: []v1alpha1.StatementEntry{
+							{
+								Effect:    "Allow",
+								Actions:   []string{"kms:Decrypt"},
+								Resources: []string{resourcePolicyKeyArn},
+							},
+						},
+					},
+				},
+			},
+			expectedResult: types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "aws-iam-role-policy",
+					ValidationRule: "validation-resourcePolicyRole",
+					Message:        "All required aws-iam-role-policy permissions were found",
+					Details:        []string{},
+					Failures:       nil,
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Pass (action granted by resource policy)",
+			rule: v1alpha1.IamRoleRule{
+				IamRoleName: "resourcePolicyRole",
+				Policies: []v1alpha1.PolicyDocument{
+					{
+						Name:    "iamPolicy",
+						Version: "1",
+						Statements: []v1alpha1.StatementEntry{
+							{
+								Effect:    "Allow",
+								Actions:   []string{"s3:GetObject"},
+								Resources: []string{"arn:aws:s3:::resourcePolicyBucket/*"},
+							},
+							{
+								Effect:    "Allow",
+								Actions:   []string{"sqs:SendMessage"},
+								Resources: []string{resourcePolicyQueue},
+							},
+						},
+					},
+				},
+				ResourcePolicies: true,
+			},
+			expectedResult: types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "aws-iam-role-policy",
+					ValidationRule: "validation-resourcePolicyRole",
+					Message:        "All required aws-iam-role-policy permissions were found",
+					Details: []string{
+						"Evaluated S3 bucket policy for resource arn:aws:s3:::resourcePolicyBucket/*",
+						"Evaluated SQS queue policy for resource " + resourcePolicyQueue,
+						"Action s3:GetObject for resource arn:aws:s3:::resourcePolicyBucket/* may be denied by a statement in the S3 bucket policy with a Condition, NotPrincipal, NotAction, or NotResource element",
+					},
+					Failures: nil,
+					Status:   corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Fail (action denied or not granted by resource policy)",
+			rule: v1alpha1.IamRoleRule{
+				IamRoleName: "resourcePolicyRole",
+				Policies: []v1alpha1.PolicyDocument{
+					{
+						Name:    "iamPolicy",
+						Version: "1",
+						Statements: []v1alpha1.StatementEntry{
+							{
+								Effect:    "Allow",
+								Actions:   []string{"kms:Decrypt"},
+								Resources: []string{resourcePolicyKeyArn},
+							},
+							{
+								Effect:    "Allow",
+								Actions:   []string{"s3:GetObject", "s3:PutObject"},
+								Resources: []string{"arn:aws:s3:::resourcePolicyBucket/*"},
+							},
+							{
+								Effect:    "Allow",
+								Actions:   []string{"sqs:ReceiveMessage"},
+								Resources: []string{resourcePolicyQueue},
+							},
+						},
+					},
+				},
+				ResourcePolicies: true,
+			},
+			expectedResult: types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "aws-iam-role-policy",
+					ValidationRule: "validation-resourcePolicyRole",
+					Message:        "One or more required IAM permissions was not found, or a condition was not met",
+					Details: []string{
+						"Evaluated KMS key policy for resource " + resourcePolicyKeyArn,
+						"Evaluated S3 bucket policy for resource arn:aws:s3:::resourcePolicyBucket/*",
+						"Evaluated SQS queue policy for resource " + resourcePolicyQueue,
+						"Action s3:GetObject for resource arn:aws:s3:::resourcePolicyBucket/* may be denied by a statement in the S3 bucket policy with a Condition, NotPrincipal, NotAction, or NotResource element",
+					},
+					Failures: []string{
+						"v1alpha1.IamRoleRule resourcePolicyRole missing action(s): [sqs:ReceiveMessage] for resource " + resourcePolicyQueue + " from policy iamPolicy",
+						"Action kms:Decrypt for resource " + resourcePolicyKeyArn + " is not granted to " + resourcePolicyRoleArn + " by the KMS key policy",
+						"Action s3:PutObject for resource arn:aws:s3:::resourcePolicyBucket/* is denied by the S3 bucket policy",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+	}
+	for _, c := range cs {
+		result, err := resourcePolicyService.ReconcileIAMRoleRule(c.rule)
+		util.CheckTestCase(t, result, c.expectedResult, err, c.expectedError)
+	}
+}
+
+func TestEvaluateResourcePolicy(t *testing.T) {
+	cs := []struct {
+		name        string
+		document    string
+		denied      bool
+		mayBeDenied bool
+		direct      bool
+		delegated   bool
+	}{
+		{
+			name:     "granted to principal",
+			document: `{"Statement": [{"Effect": "Allow", "Principal": {"AWS": "` + resourcePolicyRoleArn + `"}, "Action": "s3:GetObject", "Resource": "*"}]}`,
+			direct:   true,
+		},
+		{
+			name:      "granted to account",
+			document:  `{"Statement": [{"Effect": "Allow", "Principal": {"AWS": "123456789012"}, "Action": "s3:*", "Resource": "*"}]}`,
+			delegated: true,
+		},
+		{
+			name:     "denied",
+			document: `{"Statement": [{"Effect": "Deny", "Principal": "*", "Action": "s3:GetObject", "Resource": "arn:aws:s3:::bucket/*"}]}`,
+			denied:   true,
+		},
+		{
+			name:     "conditional allow",
+			document: `{"Statement": [{"Effect": "Allow", "Principal": "*", "Action": "s3:GetObject", "Resource": "*", "Condition": {"Bool": {"aws:SecureTransport": "true"}}}]}`,
+		},
+		{
+			name:        "conditional deny",
+			document:    `{"Statement": [{"Effect": "Deny", "Principal": "*", "Action": "s3:*", "Resource": "*", "Condition": {"Bool": {"aws:SecureTransport": "false"}}}]}`,
+			mayBeDenied: true,
+		},
+		{
+			name:        "deny with NotPrincipal",
+			document:    `{"Statement": [{"Effect": "Deny", "NotPrincipal": {"AWS": "arn:aws:iam::123456789012:role/admin"}, "Action": "s3:GetObject", "Resource": "*"}]}`,
+			mayBeDenied: true,
+		},
+		{
+			name:     "deny with NotPrincipal excluding principal",
+			document: `{"Statement": [{"Effect": "Deny", "NotPrincipal": {"AWS": "` + resourcePolicyRoleArn + `"}, "Action": "s3:GetObject", "Resource": "*"}]}`,
+		},
+		{
+			name:        "deny with NotAction",
+			document:    `{"Statement": [{"Effect": "Deny", "Principal": "*", "NotAction": "s3:List*", "Resource": "*"}]}`,
+			mayBeDenied: true,
+		},
+		{
+			name:     "deny with NotAction excluding action",
+			document: `{"Statement": [{"Effect": "Deny", "Principal": "*", "NotAction": "s3:Get*", "Resource": "*"}]}`,
+		},
+		{
+			name:        "deny with NotResource",
+			document:    `{"Statement": [{"Effect": "Deny", "Principal": "*", "Action": "s3:GetObject", "NotResource": "arn:aws:s3:::other/*"}]}`,
+			mayBeDenied: true,
+		},
+		{
+			name:     "deny with NotResource excluding resource",
+			document: `{"Statement": [{"Effect": "Deny", "Principal": "*", "Action": "s3:GetObject", "NotResource": "arn:aws:s3:::bucket/*"}]}`,
+		},
+	}
+	action := iamAction{Service: "s3", Verb: "GetObject"}
+	for _, c := range cs {
+		policy, err := parseResourcePolicyDocument(c.document)
+		if err != nil {
+			t.Fatalf("%s: failed to parse policy: %v", c.name, err)
+		}
+		denied, mayBeDenied, direct, delegated := evaluateResourcePolicy(
+			policy, action, "arn:aws:s3:::bucket/*", resourcePolicyRoleArn, "123456789012", "arn:aws:iam::123456789012:root",
+		)
+		if denied != c.denied || mayBeDenied != c.mayBeDenied || direct != c.direct || delegated != c.delegated {
+			t.Errorf("%s: expected (denied %t, mayBeDenied %t, direct %t, delegated %t), got (%t, %t, %t, %t)",
+				c.name, c.denied, c.mayBeDenied, c.direct, c.delegated, denied, mayBeDenied, direct, delegated)
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package preflight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamtypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-aws/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-aws/internal/constants"
+	aws_utils "github.com/spectrocloud-labs/validator-plugin-aws/internal/utils/aws"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
+	vapitypes "github.com/spectrocloud-labs/validator/pkg/types"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+// RuleName is the name of the validation rule reported by the preflight
+const RuleName = "plugin-credentials"
+
+// unverifiedMessage is the message of a preflight that passed without verifying the required AWS actions
+const unverifiedMessage = "Unable to verify plugin credentials"
+
+type stsApi interface {
+	GetCallerIdentity(ctx context.Context, params *sts.GetCallerIdentityInput, optFns ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error)
+}
+
+type iamApi interface {
+	GetRole(ctx context.Context, params *iam.GetRoleInput, optFns ...func(*iam.Options)) (*iam.GetRoleOutput, error)
+	SimulatePrincipalPolicy(ctx context.Context, params *iam.SimulatePrincipalPolicyInput, optFns ...func(*iam.Options)) (*iam.SimulatePrincipalPolicyOutput, error)
+}
+
+type PreflightService struct {
+	log    logr.Logger
+	stsSvc stsApi
+	iamSvc iamApi
+}
+
+func NewPreflightService(log logr.Logger, stsSvc stsApi, iamSvc iamApi) *PreflightService {
+	return &PreflightService{
+		log:    log,
+		stsSvc: stsSvc,
+		iamSvc: iamSvc,
+	}
+}
+
+// ReconcilePreflight determines the AWS actions that the rules in an AwsValidator spec require the plugin to call and
+// verifies that the plugin's own credentials allow them, by simulating the plugin principal's IAM policies.
+// The source of the plugin's credentials, if known, is reported in the result's details.
+func (s *PreflightService) ReconcilePreflight(spec v1alpha1.AwsValidatorSpec, credentialSource string) (*vapitypes.ValidationRuleResult, error) {
+
+	// Build the default latest condition for the preflight
+	state := vapi.ValidationSucceeded
+	latestCondition := vapi.DefaultValidationCondition()
+	latestCondition.Message = "Plugin credentials allow all required AWS actions"
+	latestCondition.ValidationRule = fmt.Sprintf("%s-%s", vapiconstants.ValidationRulePrefix, RuleName)
+	latestCondition.ValidationType = constants.ValidationTypePreflight
+	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
+
+	if credentialSource != "" {
+		latestCondition.Details = append(latestCondition.Details, fmt.Sprintf("Credential source: %s", credentialSource))
+	}
+
+	failures, err := s.preflightFailures(spec, &latestCondition)
+	if err != nil {
+		return validationResult, err
+	}
+
+	if len(failures) > 0 {
+		state = vapi.ValidationFailed
+		latestCondition.Failures = failures
+		latestCondition.Message = "Plugin credentials insufficient"
+		latestCondition.Status = corev1.ConditionFalse
+	}
+
+	return validationResult, nil
+}
+
+func (s *PreflightService) preflightFailures(spec v1alpha1.AwsValidatorSpec, condition *vapi.ValidationCondition) ([]string, error) {
+	identity, err := s.stsSvc.GetCallerIdentity(context.Background(), &sts.GetCallerIdentityInput{})
+	if err != nil {
+		s.log.V(0).Error(err, "failed to get caller identity")
+		return []string{fmt.Sprintf("Failed to determine the plugin's AWS identity via sts:GetCallerIdentity: %v", err)}, nil
+	}
+	if identity.Arn == nil {
+		return nil, errors.New("caller identity has no ARN")
+	}
+	callerArn := *identity.Arn
+	condition.Details = append(condition.Details, fmt.Sprintf("Plugin principal: %s", callerArn))
+
+	caller, err := aws_utils.ParseARN(callerArn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid caller identity ARN %s", callerArn)
+	}
+	partition, account := caller.Partition, caller.AccountID
+
+	principalArn, ok := s.principalArn(caller)
+	if !ok {
+		condition.Message = unverifiedMessage
+		condition.Details = append(condition.Details, fmt.Sprintf(
+			"Plugin principal %s cannot be simulated; required AWS actions were not verified", callerArn,
+		))
+		return nil, nil
+	}
+
+	required := RequiredActions(spec, partition, account)
+	resources := make([]string, 0, len(required))
+	for r := range required {
+		resources = append(resources, r)
+	}
+	sort.Strings(resources)
+
+	failures := make([]string, 0)
+	verified := 0
+	for _, resource := range resources {
+		missing, err := s.simulate(principalArn, required[resource], resource)
+		if err != nil {
+			if isAccessDenied(err) {
+				// The rules may not need iam:SimulatePrincipalPolicy themselves, so this doesn't fail the preflight
+				condition.Message = unverifiedMessage
+				condition.Details = append(condition.Details, fmt.Sprintf(
+					"Plugin principal %s is missing action iam:SimulatePrincipalPolicy; required AWS actions could not be verified", principalArn,
+				))
+				return nil, nil
+			}
+			s.log.V(0).Error(err, "failed to simulate plugin principal policy", "principalArn", principalArn)
+			return nil, err
+		}
+		if len(missing) > 0 {
+			failures = append(failures, fmt.Sprintf(
+				"Plugin principal %s is missing action(s) %s for resource %s", principalArn, missing, resource,
+			))
+		}
+		verified += len(required[resource])
+	}
+	condition.Details = append(condition.Details, fmt.Sprintf("Verified %d required AWS action(s)", verified))
+
+	return failures, nil
+}
+
+// principalArn derives the ARN of the IAM principal whose policies can be simulated from the caller identity ARN.
+// False is returned for principals that cannot be simulated, i.e., the root user and federated users.
+func (s *PreflightService) principalArn(caller arn.ARN) (string, bool) {
+	resourceType, resource, _ := strings.Cut(caller.Resource, "/")
+	switch {
+	case caller.Service == "iam" && resourceType == "user":
+		return caller.String(), true
+	case caller.Service == "sts" && resourceType == "assumed-role":
+		roleName, _, _ := strings.Cut(resource, "/")
+		role, err := s.iamSvc.GetRole(context.Background(), &iam.GetRoleInput{
+			RoleName: util.Ptr(roleName),
+		})
+		if err == nil && role.Role != nil && role.Role.Arn != nil {
+			return *role.Role.Arn, true
+		}
+		// fall back to the role's ARN without its path
+		return aws_utils.IAMArn(caller.Partition, caller.AccountID, "role", roleName), true
+	}
+	return "", false
+}
+
+// simulate returns the actions which the plugin principal's IAM policies do not allow for a resource
+func (s *PreflightService) simulate(principalArn string, actions []string, resource string) ([]string, error) {
+	simulationInput := &iam.SimulatePrincipalPolicyInput{
+		ActionNames:     actions,
+		PolicySourceArn: util.Ptr(principalArn),
+	}
+	if resource != constants.IAMWildcard {
+		simulationInput.ResourceArns = []string{resource}
+	}
+
+	var marker *string
+	missing := make([]string, 0)
+	for {
+		simulationInput.Marker = marker
+
+		simOutput, err := s.iamSvc.SimulatePrincipalPolicy(context.Background(), simulationInput)
+		if err != nil {
+			return nil, err
+		}
+		for _, result := range simOutput.EvaluationResults {
+			if result.EvalDecision != iamtypes.PolicyEvaluationDecisionTypeAllowed && result.EvalActionName != nil {
+				missing = append(missing, *result.EvalActionName)
+			}
+		}
+
+		if simOutput.IsTruncated {
+			marker = simOutput.Marker
+			continue
+		}
+		break
+	}
+	sort.Strings(missing)
+	return missing, nil
+}
+
+func isAccessDenied(err error) bool {
+	return aws_utils.ClassifyError(err) == aws_utils.ErrorReasonPluginAccessDenied
+}
+
+// CheckCallerIdentity verifies that the plugin's caller identity belongs to the account & partition expected by an AwsAuth,
+// if any. A description of the mismatch is returned if it does not. An error is returned if the caller identity cannot be determined.
+func (s *PreflightService) CheckCallerIdentity(auth v1alpha1.AwsAuth) (string, error) {
+	if auth.ExpectedAccountID == "" && auth.ExpectedPartition == "" {
+		return "", nil
+	}
+	identity, err := s.stsSvc.GetCallerIdentity(context.Background(), &sts.GetCallerIdentityInput{})
+	if err != nil {
+		s.log.V(0).Error(err, "failed to get caller identity")
+		return "", fmt.Errorf("failed to determine the plugin's AWS identity via sts:GetCallerIdentity: %w", err)
+	}
+	callerArn := ""
+	if identity.Arn != nil {
+		callerArn = *identity.Arn
+	}
+	account, partition := "", ""
+	if caller, err := aws_utils.ParseARN(callerArn); err == nil {
+		account, partition = caller.AccountID, caller.Partition
+	}
+	if identity.Account != nil {
+		account = *identity.Account
+	}
+
+	mismatches := make([]string, 0)
+	if auth.ExpectedAccountID != "" && account != auth.ExpectedAccountID {
+		mismatches = append(mismatches, fmt.Sprintf("expected account %s", auth.ExpectedAccountID))
+	}
+	if auth.ExpectedPartition != "" && partition != auth.ExpectedPartition {
+		mismatches = append(mismatches, fmt.Sprintf("expected partition %s", auth.ExpectedPartition))
+	}
+	if len(mismatches) == 0 {
+		return "", nil
+	}
+	return fmt.Sprintf(
+		"Caller identity %s (account %s, partition %s) does not match the %s",
+		callerArn, account, partition, strings.Join(mismatches, " and "),
+	), nil
+}
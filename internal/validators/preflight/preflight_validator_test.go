@@ -0,0 +1,330 @@
+package preflight
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamtypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-aws/api/v1alpha1"
+	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator/pkg/types"
+	"github.com/spectrocloud-labs/validator/pkg/util"
+)
+
+type stsApiMock struct {
+	arn string
+	err error
+}
+
+func (m stsApiMock) GetCallerIdentity(ctx context.Context, params *sts.GetCallerIdentityInput, optFns ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &sts.GetCallerIdentityOutput{Arn: util.Ptr(m.arn)}, nil
+}
+
+type iamApiMock struct {
+	roleArns      map[string]string
+	deniedActions map[string]bool
+	simulateErr   error
+}
+
+func (m iamApiMock) GetRole(ctx context.Context, params *iam.GetRoleInput, optFns ...func(*iam.Options)) (*iam.GetRoleOutput, error) {
+	arn, ok := m.roleArns[*params.RoleName]
+	if !ok {
+		return nil, &iamtypes.NoSuchEntityException{}
+	}
+	return &iam.GetRoleOutput{Role: &iamtypes.Role{Arn: util.Ptr(arn)}}, nil
+}
+
+func (m iamApiMock) SimulatePrincipalPolicy(ctx context.Context, params *iam.SimulatePrincipalPolicyInput, optFns ...func(*iam.Options)) (*iam.SimulatePrincipalPolicyOutput, error) {
+	if m.simulateErr != nil {
+		return nil, m.simulateErr
+	}
+	results := make([]iamtypes.EvaluationResult, 0, len(params.ActionNames))
+	for _, a := range params.ActionNames {
+		decision := iamtypes.PolicyEvaluationDecisionTypeAllowed
+		if m.deniedActions[a] {
+			decision = iamtypes.PolicyEvaluationDecisionTypeImplicitDeny
+		}
+		results = append(results, iamtypes.EvaluationResult{EvalActionName: util.Ptr(a), EvalDecision: decision})
+	}
+	return &iam.SimulatePrincipalPolicyOutput{EvaluationResults: results}, nil
+}
+
+type testCase struct {
+	name             string
+	spec             v1alpha1.AwsValidatorSpec
+	credentialSource string
+	svc              *PreflightService
+	expectedResult   types.ValidationRuleResult
+	expectedError    error
+}
+
+func TestReconcilePreflight(t *testing.T) {
+	spec := v1alpha1.AwsValidatorSpec{
+		IamRoleRules: []v1alpha1.IamRoleRule{
+			{IamRoleName: "validatedRole"},
+		},
+		TagRules: []v1alpha1.TagRule{
+			{Name: "subnetTags"},
+		},
+	}
+	roleArn := "arn:aws:iam::123456789012:role/path/pluginRole"
+
+	cs := []testCase{
+		{
+			name: "Pass",
+			spec: spec,
+			svc: NewPreflightService(logr.Logger{}, stsApiMock{
+				arn: "arn:aws:sts::123456789012:assumed-role/pluginRole/session",
+			}, iamApiMock{
+				roleArns: map[string]string{"pluginRole": roleArn},
+			}),
+			expectedResult: types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "aws-plugin-preflight",
+					ValidationRule: "validation-plugin-credentials",
+					Message:        "Plugin credentials allow all required AWS actions",
+					Details: []string{
+						"Plugin principal: arn:aws:sts::123456789012:assumed-role/pluginRole/session",
+						"Verified 7 required AWS action(s)",
+					},
+					Failures: nil,
+					Status:   corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name:             "Pass (credential source)",
+			spec:             spec,
+			credentialSource: "web identity for role " + roleArn + " with token file /token (resolved by WebIdentityCredentials)",
+			svc: NewPreflightService(logr.Logger{}, stsApiMock{
+				arn: "arn:aws:sts::123456789012:assumed-role/pluginRole/session",
+			}, iamApiMock{
+				roleArns: map[string]string{"pluginRole": roleArn},
+			}),
+			expectedResult: types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "aws-plugin-preflight",
+					ValidationRule: "validation-plugin-credentials",
+					Message:        "Plugin credentials allow all required AWS actions",
+					Details: []string{
+						"Credential source: web identity for role " + roleArn + " with token file /token (resolved by WebIdentityCredentials)",
+						"Plugin principal: arn:aws:sts::123456789012:assumed-role/pluginRole/session",
+						"Verified 7 required AWS action(s)",
+					},
+					Failures: nil,
+					Status:   corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Fail (missing actions)",
+			spec: spec,
+			svc: NewPreflightService(logr.Logger{}, stsApiMock{
+				arn: "arn:aws:iam::123456789012:user/pluginUser",
+			}, iamApiMock{
+				deniedActions: map[string]bool{
+					"ec2:DescribeSubnets":         true,
+					"iam:SimulatePrincipalPolicy": true,
+				},
+			}),
+			expectedResult: types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "aws-plugin-preflight",
+					ValidationRule: "validation-plugin-credentials",
+					Message:        "Plugin credentials insufficient",
+					Details: []string{
+						"Plugin principal: arn:aws:iam::123456789012:user/pluginUser",
+						"Verified 7 required AWS action(s)",
+					},
+					Failures: []string{
+						"Plugin principal arn:aws:iam::123456789012:user/pluginUser is missing action(s) [ec2:DescribeSubnets] for resource *",
+						"Plugin principal arn:aws:iam::123456789012:user/pluginUser is missing action(s) [iam:SimulatePrincipalPolicy] for resource arn:aws:iam::123456789012:role/validatedRole",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Pass (self-simulation denied)",
+			spec: spec,
+			svc: NewPreflightService(logr.Logger{}, stsApiMock{
+				arn: "arn:aws:iam::123456789012:user/pluginUser",
+			}, iamApiMock{
+				simulateErr: &smithy.GenericAPIError{Code: "AccessDenied"},
+			}),
+			expectedResult: types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "aws-plugin-preflight",
+					ValidationRule: "validation-plugin-credentials",
+					Message:        "Unable to verify plugin credentials",
+					Details: []string{
+						"Plugin principal: arn:aws:iam::123456789012:user/pluginUser",
+						"Plugin principal arn:aws:iam::123456789012:user/pluginUser is missing action iam:SimulatePrincipalPolicy; required AWS actions could not be verified",
+					},
+					Failures: nil,
+					Status:   corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Fail (no caller identity)",
+			spec: spec,
+			svc: NewPreflightService(logr.Logger{}, stsApiMock{
+				err: errors.New("no valid credential sources found"),
+			}, iamApiMock{}),
+			expectedResult: types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "aws-plugin-preflight",
+					ValidationRule: "validation-plugin-credentials",
+					Message:        "Plugin credentials insufficient",
+					Details:        []string{},
+					Failures: []string{
+						"Failed to determine the plugin's AWS identity via sts:GetCallerIdentity: no valid credential sources found",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Pass (root user)",
+			spec: spec,
+			svc: NewPreflightService(logr.Logger{}, stsApiMock{
+				arn: "arn:aws:iam::123456789012:root",
+			}, iamApiMock{}),
+			expectedResult: types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "aws-plugin-preflight",
+					ValidationRule: "validation-plugin-credentials",
+					Message:        "Unable to verify plugin credentials",
+					Details: []string{
+						"Plugin principal: arn:aws:iam::123456789012:root",
+						"Plugin principal arn:aws:iam::123456789012:root cannot be simulated; required AWS actions were not verified",
+					},
+					Failures: nil,
+					Status:   corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+	}
+	for _, c := range cs {
+		result, err := c.svc.ReconcilePreflight(c.spec, c.credentialSource)
+		util.CheckTestCase(t, result, c.expectedResult, err, c.expectedError)
+	}
+}
+
+func TestRequiredActions(t *testing.T) {
+	spec := v1alpha1.AwsValidatorSpec{
+		IamRoleRules: []v1alpha1.IamRoleRule{
+			{
+				IamRoleName: "workloadRole",
+				Overrides: &v1alpha1.RuleOverrides{
+					Auth: &v1alpha1.RuleAuth{StsAuth: &v1alpha1.AwsSTSAuth{RoleArn: "arn:aws:iam::210987654321:role/validator"}},
+				},
+			},
+		},
+		IamPolicyRules: []v1alpha1.IamPolicyRule{
+			{IamPolicyARN: "arn:aws:iam::123456789012:policy/validatedPolicy", PolicyVersion: "v2"},
+		},
+		ServiceQuotaRules: []v1alpha1.ServiceQuotaRule{
+			{
+				Name:          "vpcQuotas",
+				ServiceQuotas: []v1alpha1.ServiceQuota{{Name: "NAT gateways per Availability Zone"}},
+			},
+			{
+				Name: "quotaCodes",
+				ServiceQuotas: []v1alpha1.ServiceQuota{
+					{QuotaCode: "L-0263D0A3"},
+					{QuotaCode: "L-1216C47A"},
+				},
+			},
+		},
+	}
+	expected := map[string][]string{
+		"*": {
+			"cloudwatch:GetMetricStatistics", "ec2:DescribeAddresses", "ec2:DescribeNatGateways", "ec2:DescribeSubnets",
+			"servicequotas:GetServiceQuota", "servicequotas:ListServiceQuotas",
+		},
+		"arn:aws:iam::123456789012:policy/validatedPolicy": {"iam:GetPolicy", "iam:GetPolicyVersion", "iam:ListPolicyVersions"},
+	}
+	if actual := RequiredActions(spec, "aws", "123456789012"); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
+
+func TestServiceQuotaCodes(t *testing.T) {
+	names := make(map[string]bool, len(serviceQuotaCodes))
+	for code, name := range serviceQuotaCodes {
+		if _, ok := serviceQuotaActions[name]; !ok {
+			t.Errorf("quota code %s maps to %s, which has no required actions", code, name)
+		}
+		names[name] = true
+	}
+	for name := range serviceQuotaActions {
+		if !names[name] {
+			t.Errorf("quota %s has no quota code", name)
+		}
+	}
+}
+
+func TestCheckCallerIdentity(t *testing.T) {
+	svc := NewPreflightService(logr.Logger{}, stsApiMock{
+		arn: "arn:aws:iam::123456789012:user/pluginUser",
+	}, iamApiMock{})
+
+	cs := []struct {
+		name     string
+		auth     v1alpha1.AwsAuth
+		expected string
+	}{
+		{
+			name:     "no expectations",
+			auth:     v1alpha1.AwsAuth{},
+			expected: "",
+		},
+		{
+			name:     "match",
+			auth:     v1alpha1.AwsAuth{ExpectedAccountID: "123456789012", ExpectedPartition: "aws"},
+			expected: "",
+		},
+		{
+			name:     "account mismatch",
+			auth:     v1alpha1.AwsAuth{ExpectedAccountID: "210987654321"},
+			expected: "Caller identity arn:aws:iam::123456789012:user/pluginUser (account 123456789012, partition aws) does not match the expected account 210987654321",
+		},
+		{
+			name:     "account & partition mismatch",
+			auth:     v1alpha1.AwsAuth{ExpectedAccountID: "210987654321", ExpectedPartition: "aws-us-gov"},
+			expected: "Caller identity arn:aws:iam::123456789012:user/pluginUser (account 123456789012, partition aws) does not match the expected account 210987654321 and expected partition aws-us-gov",
+		},
+	}
+	for _, c := range cs {
+		actual, err := svc.CheckCallerIdentity(c.auth)
+		if err != nil || actual != c.expected {
+			t.Errorf("%s: expected (%q, nil), got (%q, %v)", c.name, c.expected, actual, err)
+		}
+	}
+
+	throttled := &smithy.GenericAPIError{Code: "Throttling", Message: "Rate exceeded"}
+	failing := NewPreflightService(logr.Logger{}, stsApiMock{err: throttled}, iamApiMock{})
+	mismatch, err := failing.CheckCallerIdentity(v1alpha1.AwsAuth{ExpectedAccountID: "123456789012"})
+	if mismatch != "" || !errors.Is(err, throttled) {
+		t.Errorf("failed caller identity: expected (\"\", %v), got (%q, %v)", throttled, mismatch, err)
+	}
+}
@@ -0,0 +1,222 @@
+package preflight
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spectrocloud-labs/validator-plugin-aws/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-aws/internal/constants"
+	aws_utils "github.com/spectrocloud-labs/validator-plugin-aws/internal/utils/aws"
+)
+
+// serviceQuotaActions maps AWS service quota names to the actions required to compute their usage.
+// The usage of any other quota is read from CloudWatch via cloudwatch:GetMetricStatistics.
+var serviceQuotaActions = map[string][]string{
+	// EC2
+	"EC2-VPC Elastic IPs": {"ec2:DescribeAddresses"},
+	"Public AMIs":         {"ec2:DescribeImages"},
+	// EFS
+	"File systems per account": {"elasticfilesystem:DescribeFileSystems"},
+	// ELB
+	"Application Load Balancers per Region": {"elasticloadbalancing:DescribeLoadBalancers"},
+	"Classic Load Balancers per Region":     {"elasticloadbalancing:DescribeLoadBalancers"},
+	"Network Load Balancers per Region":     {"elasticloadbalancing:DescribeLoadBalancers"},
+	// VPC
+	"Internet gateways per Region":       {"ec2:DescribeInternetGateways"},
+	"Network interfaces per Region":      {"ec2:DescribeNetworkInterfaces"},
+	"VPCs per Region":                    {"ec2:DescribeVpcs"},
+	"Subnets per VPC":                    {"ec2:DescribeSubnets"},
+	"NAT gateways per Availability Zone": {"ec2:DescribeNatGateways", "ec2:DescribeSubnets"},
+}
+
+// serviceQuotaCodes maps the codes of the service quotas in serviceQuotaActions to their names, so that the actions
+// required by rules that specify a quota by code can be determined before the quota is looked up
+var serviceQuotaCodes = map[string]string{
+	// EC2
+	"L-0263D0A3": "EC2-VPC Elastic IPs",
+	"L-0E3CBAB9": "Public AMIs",
+	// EFS
+	"L-848C634D": "File systems per account",
+	// ELB
+	"L-53DA6B97": "Application Load Balancers per Region",
+	"L-E9E9831D": "Classic Load Balancers per Region",
+	"L-69A177A2": "Network Load Balancers per Region",
+	// VPC
+	"L-A4707A72": "Internet gateways per Region",
+	"L-DF5E4CA3": "Network interfaces per Region",
+	"L-F678F1CE": "VPCs per Region",
+	"L-407747CB": "Subnets per VPC",
+	"L-FE5A380F": "NAT gateways per Availability Zone",
+}
+
+var resourcePolicyActions = []string{
+	"kms:GetKeyPolicy",
+	"s3:GetBucketLocation",
+	"s3:GetBucketPolicy",
+	"sqs:GetQueueAttributes",
+	"sqs:GetQueueUrl",
+}
+
+type lastAccessedRule interface {
+	LastAccessedCheck() *v1alpha1.LastAccessedCheck
+}
+
+type resourcePolicyRule interface {
+	ResourcePoliciesEnabled() bool
+}
+
+// RequiredActions determines the AWS actions that the rules in an AwsValidator spec require the plugin to call.
+// Actions are keyed by the resource they are called on, or * if they are not scoped to a specific resource.
+// Rules with an auth override are evaluated with other credentials, so their actions are not required.
+func RequiredActions(spec v1alpha1.AwsValidatorSpec, partition, account string) map[string][]string {
+	required := make(map[string]map[string]bool)
+	add := func(resource string, actions ...string) {
+		if required[resource] == nil {
+			required[resource] = make(map[string]bool)
+		}
+		for _, a := range actions {
+			required[resource][a] = true
+		}
+	}
+	iamArn := func(resourceType, name string) string {
+		return aws_utils.IAMArn(partition, account, resourceType, name)
+	}
+	principalActions := func(rule any, resource string, actions ...string) {
+		add(resource, actions...)
+		add(resource, "iam:GetPolicy", "iam:GetPolicyVersion", "iam:SimulatePrincipalPolicy")
+		if lr, ok := rule.(lastAccessedRule); ok && lr.LastAccessedCheck() != nil {
+			add(resource, "iam:GenerateServiceLastAccessedDetails", "iam:GetServiceLastAccessedDetails")
+		}
+		if rr, ok := rule.(resourcePolicyRule); ok && rr.ResourcePoliciesEnabled() {
+			add(constants.IAMWildcard, resourcePolicyActions...)
+		}
+	}
+
+	for _, rule := range spec.IamRoleRules {
+		if rule.Overrides.AuthOverride() != nil {
+			continue
+		}
+		principalActions(rule, iamArn("role", rule.Name()), "iam:GetRole", "iam:GetContextKeysForPrincipalPolicy", "iam:ListAttachedRolePolicies")
+	}
+	for _, rule := range spec.IamUserRules {
+		if rule.Overrides.AuthOverride() != nil {
+			continue
+		}
+		principalActions(rule, iamArn("user", rule.Name()), "iam:GetUser", "iam:GetContextKeysForPrincipalPolicy", "iam:ListAttachedUserPolicies")
+	}
+	for _, rule := range spec.IamGroupRules {
+		if rule.Overrides.AuthOverride() != nil {
+			continue
+		}
+		principalActions(rule, iamArn("group", rule.Name()), "iam:GetGroup", "iam:ListAttachedGroupPolicies")
+	}
+	for _, rule := range spec.IamPolicyRules {
+		if rule.Overrides.AuthOverride() != nil {
+			continue
+		}
+		add(rule.Name(), "iam:GetPolicy", "iam:GetPolicyVersion")
+		if rule.PolicyVersion != "" {
+			add(rule.Name(), "iam:ListPolicyVersions")
+		}
+	}
+	for _, rule := range spec.IamIrsaRules {
+		if rule.Overrides.AuthOverride() != nil {
+			continue
+		}
+		principalActions(rule, iamArn("role", rule.Name()), "iam:GetRole", "iam:GetContextKeysForPrincipalPolicy", "iam:ListAttachedRolePolicies")
+		issuer := strings.TrimSuffix(strings.TrimPrefix(rule.OidcIssuerURL, "https://"), "/")
+		add(iamArn("oidc-provider", issuer), "iam:GetOpenIDConnectProvider")
+	}
+	for _, rule := range spec.IamCustomPolicyRules {
+		if rule.Overrides.AuthOverride() != nil {
+			continue
+		}
+		if rule.Simulate {
+			add(constants.IAMWildcard, "iam:SimulateCustomPolicy")
+		}
+		if rule.Simulate && rule.SimulationPrincipalArn != "" {
+			add(rule.SimulationPrincipalArn, "iam:SimulatePrincipalPolicy")
+		}
+	}
+	for _, rule := range spec.IamUserCredentialRules {
+		if rule.Overrides.AuthOverride() != nil {
+			continue
+		}
+		add(iamArn("user", rule.Name()), "iam:ListAccessKeys", "iam:GetAccessKeyLastUsed", "iam:ListMFADevices")
+		if rule.MaxPasswordAgeDays > 0 {
+			add(constants.IAMWildcard, "iam:GenerateCredentialReport", "iam:GetCredentialReport")
+		}
+	}
+	for _, rule := range spec.IamExistenceRules {
+		if rule.Overrides.AuthOverride() != nil {
+			continue
+		}
+		for _, r := range rule.ServiceLinkedRoles {
+			add(iamArn("role", r), "iam:GetRole")
+		}
+		for _, p := range rule.InstanceProfiles {
+			add(iamArn("instance-profile", p.Name), "iam:GetInstanceProfile")
+		}
+	}
+	for _, rule := range spec.IamQuotaRules {
+		if rule.Overrides.AuthOverride() != nil {
+			continue
+		}
+		if len(rule.AccountQuotas) > 0 {
+			add(constants.IAMWildcard, "iam:GetAccountSummary")
+		}
+		for _, p := range rule.Principals {
+			resourceType := strings.ToLower(p.Type)
+			add(iamArn(resourceType, p.Name), fmt.Sprintf("iam:ListAttached%sPolicies", p.Type))
+			if p.InlinePolicySizeBuffer > 0 {
+				add(iamArn(resourceType, p.Name), fmt.Sprintf("iam:List%sPolicies", p.Type), fmt.Sprintf("iam:Get%sPolicy", p.Type))
+			}
+		}
+	}
+	for _, rule := range spec.AccountRules {
+		if rule.Overrides.AuthOverride() != nil {
+			continue
+		}
+		if rule.PasswordPolicy != nil {
+			add(constants.IAMWildcard, "iam:GetAccountPasswordPolicy")
+		}
+		if rule.RequireRootMFA || rule.DisallowRootAccessKeys {
+			add(constants.IAMWildcard, "iam:GetAccountSummary")
+		}
+	}
+	for _, rule := range spec.ServiceQuotaRules {
+		if rule.Overrides.AuthOverride() != nil {
+			continue
+		}
+		for _, q := range rule.ServiceQuotas {
+			name := q.Name
+			if q.QuotaCode != "" {
+				add(constants.IAMWildcard, "servicequotas:GetServiceQuota")
+				name = serviceQuotaCodes[q.QuotaCode]
+			} else {
+				add(constants.IAMWildcard, "servicequotas:ListServiceQuotas")
+			}
+			if actions, ok := serviceQuotaActions[name]; ok {
+				add(constants.IAMWildcard, actions...)
+			} else {
+				add(constants.IAMWildcard, "cloudwatch:GetMetricStatistics")
+			}
+		}
+	}
+	for _, rule := range spec.TagRules {
+		if rule.Overrides.AuthOverride() != nil {
+			continue
+		}
+		add(constants.IAMWildcard, "ec2:DescribeSubnets")
+	}
+
+	actions := make(map[string][]string, len(required))
+	for resource, actionSet := range required {
+		for a := range actionSet {
+			actions[resource] = append(actions[resource], a)
+		}
+		sort.Strings(actions[resource])
+	}
+	return actions
+}
@@ -3,7 +3,13 @@ package servicequota
 import (
 	"context"
 	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cwtypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/efs"
 	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancing"
@@ -20,6 +26,7 @@ import (
 	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
 	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
 	vapitypes "github.com/spectrocloud-labs/validator/pkg/types"
+	"github.com/spectrocloud-labs/validator/pkg/util"
 )
 
 type ec2Api interface {
@@ -45,9 +52,14 @@ type elbv2Api interface {
 }
 
 type sqApi interface {
+	GetServiceQuota(context.Context, *servicequotas.GetServiceQuotaInput, ...func(*servicequotas.Options)) (*servicequotas.GetServiceQuotaOutput, error)
 	ListServiceQuotas(context.Context, *servicequotas.ListServiceQuotasInput, ...func(*servicequotas.Options)) (*servicequotas.ListServiceQuotasOutput, error)
 }
 
+type cloudwatchApi interface {
+	GetMetricStatistics(ctx context.Context, params *cloudwatch.GetMetricStatisticsInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricStatisticsOutput, error)
+}
+
 type ServiceQuotaRuleService struct {
 	log      logr.Logger
 	ec2Svc   ec2Api
@@ -55,9 +67,10 @@ type ServiceQuotaRuleService struct {
 	elbSvc   elbApi
 	elbv2Svc elbv2Api
 	sqSvc    sqApi
+	cwSvc    cloudwatchApi
 }
 
-func NewServiceQuotaRuleService(log logr.Logger, ec2Svc ec2Api, efsSvc efsApi, elbSvc elbApi, elbv2Svc elbv2Api, sqSvc sqApi) *ServiceQuotaRuleService {
+func NewServiceQuotaRuleService(log logr.Logger, ec2Svc ec2Api, efsSvc efsApi, elbSvc elbApi, elbv2Svc elbv2Api, sqSvc sqApi, cwSvc cloudwatchApi) *ServiceQuotaRuleService {
 	return &ServiceQuotaRuleService{
 		log:      log,
 		ec2Svc:   ec2Svc,
@@ -65,11 +78,13 @@ func NewServiceQuotaRuleService(log logr.Logger, ec2Svc ec2Api, efsSvc efsApi, e
 		elbSvc:   elbSvc,
 		elbv2Svc: elbv2Svc,
 		sqSvc:    sqSvc,
+		cwSvc:    cwSvc,
 	}
 }
 
-// execQuotaUsageFunc maps AWS service quota names to functions that compute the usage and/or maximum usage for each service (maximum if the quota is broken out by VPC, AZ, etc.)
-func (s *ServiceQuotaRuleService) execQuotaUsageFunc(quotaName string, rule v1alpha1.ServiceQuotaRule) (*types.UsageResult, error) {
+// execQuotaUsageFunc maps AWS service quota names to functions that compute the usage and/or maximum usage for each service (maximum if the quota is broken out by VPC, AZ, etc.).
+// The usage of any other quota is read from the CloudWatch usage metric published for the quota, if any.
+func (s *ServiceQuotaRuleService) execQuotaUsageFunc(quotaName string, quota *sqtypes.ServiceQuota, rule v1alpha1.ServiceQuotaRule) (*types.UsageResult, error) {
 	switch quotaName {
 	// EC2
 	case "EC2-VPC Elastic IPs":
@@ -98,7 +113,10 @@ func (s *ServiceQuotaRuleService) execQuotaUsageFunc(quotaName string, rule v1al
 	case "NAT gateways per Availability Zone":
 		return s.natGatewaysPerAz(rule)
 	default:
-		return nil, fmt.Errorf("invalid service quota name: %s", rule.ServiceCode)
+		if quota == nil {
+			return nil, fmt.Errorf("invalid service quota name: %s", quotaName)
+		}
+		return s.usageMetric(quota, rule)
 	}
 }
 
@@ -113,66 +131,243 @@ func (s *ServiceQuotaRuleService) ReconcileServiceQuotaRule(rule v1alpha1.Servic
 	latestCondition.ValidationType = constants.ValidationTypeServiceQuota
 	validationResult := &vapitypes.ValidationRuleResult{Condition: &latestCondition, State: &state}
 
-	// Fetch the quota by service code & name & compare against usage
+	// Fetch the quota by service code & quota code or name & compare against usage
 	failures := make([]string, 0)
+	notFound := 0
 
-	sqPager := servicequotas.NewListServiceQuotasPaginator(s.sqSvc, &servicequotas.ListServiceQuotasInput{
-		ServiceCode: &rule.ServiceCode,
-	})
-
-	quotaMap := make(map[string]sqtypes.ServiceQuota, 0)
-	for sqPager.HasMorePages() {
-		page, err := sqPager.NextPage(context.Background())
-		if err != nil {
-			s.log.V(0).Error(err, "failed to get service quotas", "region", rule.Region, "serviceCode", rule.ServiceCode)
-			return validationResult, err
-		}
-		for _, q := range page.Quotas {
-			if q.QuotaName != nil {
-				quotaMap[*q.QuotaName] = q
-			}
-		}
+	quotaMap, err := s.quotasByName(rule)
+	if err != nil {
+		return validationResult, err
 	}
 
 	for _, ruleQuota := range rule.ServiceQuotas {
-		usageResult, err := s.execQuotaUsageFunc(ruleQuota.Name, rule)
+		quota, err := s.serviceQuota(rule, ruleQuota, quotaMap)
 		if err != nil {
-			s.log.V(0).Error(err, "failed to get usage for service quota", "region", rule.Region, "serviceCode", rule.ServiceCode, "quotaName", ruleQuota.Name)
 			return validationResult, err
 		}
-
-		quota, ok := quotaMap[ruleQuota.Name]
-		if !ok {
+		if quota == nil {
 			s.log.V(0).Info("failed to get service quota", "region", rule.Region, "serviceCode", rule.ServiceCode, "quotaName", ruleQuota.Name)
+			failures = append(failures, fmt.Sprintf(
+				"Service quota %s not found for service %s; it may have been renamed, specify its quotaCode instead",
+				ruleQuota.Name, rule.ServiceCode,
+			))
+			notFound++
 			continue
 		}
+		quotaName := *quota.QuotaName
 
-		remainder := *quota.Value - usageResult.MaxUsage
-		if remainder < float64(ruleQuota.Buffer) {
-			failureMsg := fmt.Sprintf(
-				"Remaining quota %d, less than buffer %d, for service %s and quota %s",
-				int(remainder), ruleQuota.Buffer, rule.ServiceCode, ruleQuota.Name,
-			)
-			failures = append(failures, failureMsg)
+		usageResult, err := s.execQuotaUsageFunc(quotaName, quota, rule)
+		if err != nil {
+			s.log.V(0).Error(err, "failed to get usage for service quota", "region", rule.Region, "serviceCode", rule.ServiceCode, "quotaName", quotaName)
+			return validationResult, err
 		}
 
+		failures = append(failures, headroomFailures(ruleQuota, *quota.Value, usageResult.MaxUsage, rule.ServiceCode, quotaName)...)
+
+		// Record the quota code, so that name-based rules can be migrated to quota codes
+		quotaLabel := quotaName
+		if quota.QuotaCode != nil {
+			quotaLabel = fmt.Sprintf("%s (%s)", quotaName, *quota.QuotaCode)
+		}
 		quotaDetail := fmt.Sprintf(
-			"%s: quota: %d, buffer: %d, max. usage: %d, max. usage entity: %s",
-			ruleQuota.Name, int(*quota.Value), ruleQuota.Buffer, int(usageResult.MaxUsage), usageResult.Description,
+			"%s: quota: %d, %s, max. usage: %d, max. usage entity: %s",
+			quotaLabel, int(*quota.Value), headroomRequirements(ruleQuota), int(usageResult.MaxUsage), usageResult.Description,
 		)
 		latestCondition.Details = append(latestCondition.Details, quotaDetail)
+		if usageResult.NoData {
+			latestCondition.Details = append(latestCondition.Details, fmt.Sprintf(
+				"%s: no usage datapoints were published to CloudWatch in the last %d minutes; the quota is assumed to be unused",
+				quotaLabel, int(usageMetricWindow.Minutes()),
+			))
+		}
 	}
 
 	if len(failures) > 0 {
 		state = vapi.ValidationFailed
 		latestCondition.Failures = failures
 		latestCondition.Message = "Usage for one or more service quotas exceeded the specified buffer"
+		if notFound == len(failures) {
+			latestCondition.Message = "One or more service quotas were not found"
+		}
 		latestCondition.Status = corev1.ConditionFalse
 	}
 
 	return validationResult, nil
 }
 
+// headroomFailures compares the headroom remaining below a quota's value against each of a rule quota's headroom requirements.
+// Failures state the remaining headroom in the same unit as the requirement.
+func headroomFailures(ruleQuota v1alpha1.ServiceQuota, quotaValue, maxUsage float64, serviceCode, quotaName string) []string {
+	failures := make([]string, 0)
+	remainder := quotaValue - maxUsage
+
+	if remainder < float64(ruleQuota.Buffer) {
+		failures = append(failures, fmt.Sprintf(
+			"Remaining quota %d, less than buffer %d, for service %s and quota %s",
+			int(remainder), ruleQuota.Buffer, serviceCode, quotaName,
+		))
+	}
+	if ruleQuota.BufferPercent > 0 {
+		var remainderPercent float64
+		if quotaValue > 0 {
+			remainderPercent = remainder / quotaValue * 100
+		}
+		if remainderPercent < float64(ruleQuota.BufferPercent) {
+			failures = append(failures, fmt.Sprintf(
+				"Remaining quota %.1f%%, less than buffer %d%%, for service %s and quota %s",
+				remainderPercent, ruleQuota.BufferPercent, serviceCode, quotaName,
+			))
+		}
+	}
+	if remainder < float64(ruleQuota.Required) {
+		failures = append(failures, fmt.Sprintf(
+			"Remaining quota %d, less than required %d, for service %s and quota %s",
+			int(remainder), ruleQuota.Required, serviceCode, quotaName,
+		))
+	}
+	return failures
+}
+
+// headroomRequirements describes a rule quota's headroom requirements
+func headroomRequirements(ruleQuota v1alpha1.ServiceQuota) string {
+	requirements := make([]string, 0)
+	if ruleQuota.Buffer != 0 || (ruleQuota.BufferPercent == 0 && ruleQuota.Required == 0) {
+		requirements = append(requirements, fmt.Sprintf("buffer: %d", ruleQuota.Buffer))
+	}
+	if ruleQuota.BufferPercent > 0 {
+		requirements = append(requirements, fmt.Sprintf("buffer: %d%%", ruleQuota.BufferPercent))
+	}
+	if ruleQuota.Required > 0 {
+		requirements = append(requirements, fmt.Sprintf("required: %d", ruleQuota.Required))
+	}
+	return strings.Join(requirements, ", ")
+}
+
+// quotasByName lists the quotas of a rule's service by name, if any of the rule's quotas are identified by name only
+func (s *ServiceQuotaRuleService) quotasByName(rule v1alpha1.ServiceQuotaRule) (map[string]sqtypes.ServiceQuota, error) {
+	quotaMap := make(map[string]sqtypes.ServiceQuota, 0)
+	if !slices.ContainsFunc(rule.ServiceQuotas, func(q v1alpha1.ServiceQuota) bool { return q.QuotaCode == "" }) {
+		return quotaMap, nil
+	}
+
+	sqPager := servicequotas.NewListServiceQuotasPaginator(s.sqSvc, &servicequotas.ListServiceQuotasInput{
+		ServiceCode: &rule.ServiceCode,
+	})
+	for sqPager.HasMorePages() {
+		page, err := sqPager.NextPage(context.Background())
+		if err != nil {
+			s.log.V(0).Error(err, "failed to get service quotas", "region", rule.Region, "serviceCode", rule.ServiceCode)
+			return nil, err
+		}
+		for _, q := range page.Quotas {
+			if q.QuotaName != nil {
+				quotaMap[*q.QuotaName] = q
+			}
+		}
+	}
+	return quotaMap, nil
+}
+
+// serviceQuota returns a rule's service quota by quota code, if specified, or by name.
+// Nil is returned if no quota with the name exists.
+func (s *ServiceQuotaRuleService) serviceQuota(rule v1alpha1.ServiceQuotaRule, ruleQuota v1alpha1.ServiceQuota, quotaMap map[string]sqtypes.ServiceQuota) (*sqtypes.ServiceQuota, error) {
+	if ruleQuota.QuotaCode == "" {
+		if quota, ok := quotaMap[ruleQuota.Name]; ok {
+			return &quota, nil
+		}
+		return nil, nil
+	}
+
+	output, err := s.sqSvc.GetServiceQuota(context.Background(), &servicequotas.GetServiceQuotaInput{
+		ServiceCode: &rule.ServiceCode,
+		QuotaCode:   &ruleQuota.QuotaCode,
+	})
+	if err != nil {
+		s.log.V(0).Error(err, "failed to get service quota", "region", rule.Region, "serviceCode", rule.ServiceCode, "quotaCode", ruleQuota.QuotaCode)
+		return nil, err
+	}
+	if output.Quota == nil || output.Quota.QuotaName == nil || output.Quota.Value == nil {
+		return nil, fmt.Errorf("service quota %s for service %s has no name or value", ruleQuota.QuotaCode, rule.ServiceCode)
+	}
+	return output.Quota, nil
+}
+
+// CloudWatch
+
+// usageMetricPeriod is the period of the CloudWatch usage metric statistics used to determine a quota's usage.
+// AWS/Usage metrics are published every minute, so the latest statistic reflects the current usage.
+const usageMetricPeriod = 5 * time.Minute
+
+// usageMetricWindow is the time range searched for the latest CloudWatch usage metric statistic
+const usageMetricWindow = 3 * usageMetricPeriod
+
+// usageMetric determines the usage of a service quota from the CloudWatch metric that the quota's usage is published to,
+// typically in the AWS/Usage namespace
+func (s *ServiceQuotaRuleService) usageMetric(quota *sqtypes.ServiceQuota, rule v1alpha1.ServiceQuotaRule) (*types.UsageResult, error) {
+	um := quota.UsageMetric
+	if um == nil || um.MetricNamespace == nil || um.MetricName == nil {
+		return nil, fmt.Errorf("service quota %s has no usage metric, so its usage can't be determined", *quota.QuotaName)
+	}
+
+	statistic := cwtypes.StatisticMaximum
+	if um.MetricStatisticRecommendation != nil {
+		statistic = cwtypes.Statistic(*um.MetricStatisticRecommendation)
+	}
+	dimensions := make([]cwtypes.Dimension, 0, len(um.MetricDimensions))
+	for k, v := range um.MetricDimensions {
+		dimensions = append(dimensions, cwtypes.Dimension{Name: util.Ptr(k), Value: util.Ptr(v)})
+	}
+	sort.Slice(dimensions, func(i, j int) bool { return *dimensions[i].Name < *dimensions[j].Name })
+
+	end := time.Now()
+	output, err := s.cwSvc.GetMetricStatistics(context.Background(), &cloudwatch.GetMetricStatisticsInput{
+		Namespace:  um.MetricNamespace,
+		MetricName: um.MetricName,
+		Dimensions: dimensions,
+		StartTime:  util.Ptr(end.Add(-usageMetricWindow)),
+		EndTime:    util.Ptr(end),
+		Period:     util.Ptr(int32(usageMetricPeriod.Seconds())),
+		Statistics: []cwtypes.Statistic{statistic},
+	})
+	if err != nil {
+		s.log.V(0).Error(err, "failed to get usage metric statistics", "region", rule.Region, "namespace", *um.MetricNamespace, "metricName", *um.MetricName)
+		return nil, err
+	}
+
+	// No datapoints are published while a quota is unused
+	var latest *cwtypes.Datapoint
+	for i, dp := range output.Datapoints {
+		if dp.Timestamp != nil && (latest == nil || dp.Timestamp.After(*latest.Timestamp)) {
+			latest = &output.Datapoints[i]
+		}
+	}
+	if latest == nil {
+		return &types.UsageResult{Description: rule.Region, NoData: true}, nil
+	}
+	return &types.UsageResult{Description: rule.Region, MaxUsage: datapointValue(*latest, statistic)}, nil
+}
+
+// datapointValue returns the value of a statistic in a CloudWatch datapoint
+func datapointValue(dp cwtypes.Datapoint, statistic cwtypes.Statistic) float64 {
+	var value *float64
+	switch statistic {
+	case cwtypes.StatisticAverage:
+		value = dp.Average
+	case cwtypes.StatisticMinimum:
+		value = dp.Minimum
+	case cwtypes.StatisticSampleCount:
+		value = dp.SampleCount
+	case cwtypes.StatisticSum:
+		value = dp.Sum
+	default:
+		value = dp.Maximum
+	}
+	if value == nil {
+		return 0
+	}
+	return *value
+}
+
 // EC2
 
 // elasticIPsPerRegion determines the number of elastic IPs in use in a region
@@ -2,9 +2,13 @@ package servicequota
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"testing"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cwtypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/aws/aws-sdk-go-v2/service/efs"
@@ -90,10 +94,27 @@ type sqApiMock struct {
 	serviceQuotas *servicequotas.ListServiceQuotasOutput
 }
 
+func (m sqApiMock) GetServiceQuota(ctx context.Context, params *servicequotas.GetServiceQuotaInput, optFns ...func(*servicequotas.Options)) (*servicequotas.GetServiceQuotaOutput, error) {
+	for _, q := range m.serviceQuotas.Quotas {
+		if q.QuotaCode != nil && *q.QuotaCode == *params.QuotaCode {
+			return &servicequotas.GetServiceQuotaOutput{Quota: &q}, nil
+		}
+	}
+	return nil, &sqtypes.NoSuchResourceException{Message: util.Ptr("quota not found")}
+}
+
 func (m sqApiMock) ListServiceQuotas(context.Context, *servicequotas.ListServiceQuotasInput, ...func(*servicequotas.Options)) (*servicequotas.ListServiceQuotasOutput, error) {
 	return m.serviceQuotas, nil
 }
 
+type cloudwatchApiMock struct {
+	datapoints map[string][]cwtypes.Datapoint
+}
+
+func (m cloudwatchApiMock) GetMetricStatistics(ctx context.Context, params *cloudwatch.GetMetricStatisticsInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricStatisticsOutput, error) {
+	return &cloudwatch.GetMetricStatisticsOutput{Datapoints: m.datapoints[*params.MetricName]}, nil
+}
+
 var svcQuotaService = NewServiceQuotaRuleService(
 	logr.Logger{},
 	ec2ApiMock{
@@ -198,8 +219,34 @@ var svcQuotaService = NewServiceQuotaRuleService(
 	sqApiMock{
 		serviceQuotas: mockQuotas,
 	},
+	cloudwatchApiMock{
+		datapoints: map[string][]cwtypes.Datapoint{
+			"ResourceCount": {
+				{
+					Maximum:   util.Ptr(58.0),
+					Timestamp: util.Ptr(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)),
+				},
+				{
+					Maximum:   util.Ptr(60.0),
+					Timestamp: util.Ptr(time.Date(2024, 3, 1, 12, 5, 0, 0, time.UTC)),
+				},
+			},
+		},
+	},
 )
 
+var onDemandInstancesUsageMetric = &sqtypes.MetricInfo{
+	MetricNamespace: util.Ptr("AWS/Usage"),
+	MetricName:      util.Ptr("ResourceCount"),
+	MetricDimensions: map[string]string{
+		"Class":    "Standard/OnDemand",
+		"Resource": "vCPU",
+		"Service":  "EC2",
+		"Type":     "Resource",
+	},
+	MetricStatisticRecommendation: util.Ptr("Maximum"),
+}
+
 var mockQuotas = &servicequotas.ListServiceQuotasOutput{}
 
 type testCase struct {
@@ -894,6 +941,293 @@ func TestQuotaValidation(t *testing.T) {
 				State: util.Ptr(vapi.ValidationSucceeded),
 			},
 		},
+		{
+			name: "Fail (insufficient On-Demand vCPUs via usage metric)",
+			rule: v1alpha1.ServiceQuotaRule{
+				Name:        "ec2-instances",
+				Region:      "us-west-1",
+				ServiceCode: "ec2",
+				ServiceQuotas: []v1alpha1.ServiceQuota{
+					{
+						Name:   "Running On-Demand Standard (A, C, D, H, I, M, R, T, Z) instances",
+						Buffer: 8,
+					},
+				},
+			},
+			mockQuotas: []sqtypes.ServiceQuota{
+				{
+					QuotaName:   util.Ptr("Running On-Demand Standard (A, C, D, H, I, M, R, T, Z) instances"),
+					Value:       util.Ptr(64.0),
+					UsageMetric: onDemandInstancesUsageMetric,
+				},
+			},
+			expectedResult: types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "aws-service-quota",
+					ValidationRule: "validation-ec2-instances",
+					Message:        "Usage for one or more service quotas exceeded the specified buffer",
+					Details:        []string{"Running On-Demand Standard (A, C, D, H, I, M, R, T, Z) instances: quota: 64, buffer: 8, max. usage: 60, max. usage entity: us-west-1"},
+					Failures:       []string{"Remaining quota 4, less than buffer 8, for service ec2 and quota Running On-Demand Standard (A, C, D, H, I, M, R, T, Z) instances"},
+					Status:         corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Pass (sufficient On-Demand vCPUs via usage metric)",
+			rule: v1alpha1.ServiceQuotaRule{
+				Name:        "ec2-instances",
+				Region:      "us-west-1",
+				ServiceCode: "ec2",
+				ServiceQuotas: []v1alpha1.ServiceQuota{
+					{
+						Name:   "Running On-Demand Standard (A, C, D, H, I, M, R, T, Z) instances",
+						Buffer: 8,
+					},
+				},
+			},
+			mockQuotas: []sqtypes.ServiceQuota{
+				{
+					QuotaName:   util.Ptr("Running On-Demand Standard (A, C, D, H, I, M, R, T, Z) instances"),
+					Value:       util.Ptr(128.0),
+					UsageMetric: onDemandInstancesUsageMetric,
+				},
+			},
+			expectedResult: types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "aws-service-quota",
+					ValidationRule: "validation-ec2-instances",
+					Message:        "Usage for all service quotas is below specified buffer",
+					Details:        []string{"Running On-Demand Standard (A, C, D, H, I, M, R, T, Z) instances: quota: 128, buffer: 8, max. usage: 60, max. usage entity: us-west-1"},
+					Failures:       nil,
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Pass (no usage datapoints for usage metric)",
+			rule: v1alpha1.ServiceQuotaRule{
+				Name:        "ec2-hosts",
+				Region:      "us-west-1",
+				ServiceCode: "ec2",
+				ServiceQuotas: []v1alpha1.ServiceQuota{
+					{
+						QuotaCode: "L-DE82EABA",
+						Buffer:    2,
+					},
+				},
+			},
+			mockQuotas: []sqtypes.ServiceQuota{
+				{
+					QuotaCode: util.Ptr("L-DE82EABA"),
+					QuotaName: util.Ptr("Running Dedicated mac1 Hosts"),
+					Value:     util.Ptr(3.0),
+					UsageMetric: &sqtypes.MetricInfo{
+						MetricNamespace: util.Ptr("AWS/Usage"),
+						MetricName:      util.Ptr("DedicatedHostCount"),
+					},
+				},
+			},
+			expectedResult: types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "aws-service-quota",
+					ValidationRule: "validation-ec2-hosts",
+					Message:        "Usage for all service quotas is below specified buffer",
+					Details: []string{
+						"Running Dedicated mac1 Hosts (L-DE82EABA): quota: 3, buffer: 2, max. usage: 0, max. usage entity: us-west-1",
+						"Running Dedicated mac1 Hosts (L-DE82EABA): no usage datapoints were published to CloudWatch in the last 15 minutes; the quota is assumed to be unused",
+					},
+					Failures: nil,
+					Status:   corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Pass (sufficient EIPs by quota code)",
+			rule: v1alpha1.ServiceQuotaRule{
+				Name:        "ec2",
+				Region:      "us-west-1",
+				ServiceCode: "ec2",
+				ServiceQuotas: []v1alpha1.ServiceQuota{
+					{
+						QuotaCode: "L-0263D0A3",
+						Buffer:    3,
+					},
+				},
+			},
+			mockQuotas: []sqtypes.ServiceQuota{
+				{
+					QuotaCode: util.Ptr("L-0263D0A3"),
+					QuotaName: util.Ptr("EC2-VPC Elastic IPs"),
+					Value:     util.Ptr(5.0),
+				},
+			},
+			expectedResult: types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "aws-service-quota",
+					ValidationRule: "validation-ec2",
+					Message:        "Usage for all service quotas is below specified buffer",
+					Details:        []string{"EC2-VPC Elastic IPs (L-0263D0A3): quota: 5, buffer: 3, max. usage: 1, max. usage entity: us-west-1"},
+					Failures:       nil,
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Fail (renamed quota)",
+			rule: v1alpha1.ServiceQuotaRule{
+				Name:        "ec2",
+				Region:      "us-west-1",
+				ServiceCode: "ec2",
+				ServiceQuotas: []v1alpha1.ServiceQuota{
+					{
+						Name:   "EC2-VPC Elastic IPs",
+						Buffer: 3,
+					},
+				},
+			},
+			mockQuotas: []sqtypes.ServiceQuota{
+				{
+					QuotaCode: util.Ptr("L-0263D0A3"),
+					QuotaName: util.Ptr("EC2 Elastic IPs"),
+					Value:     util.Ptr(5.0),
+				},
+			},
+			expectedResult: types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "aws-service-quota",
+					ValidationRule: "validation-ec2",
+					Message:        "One or more service quotas were not found",
+					Details:        []string{},
+					Failures:       []string{"Service quota EC2-VPC Elastic IPs not found for service ec2; it may have been renamed, specify its quotaCode instead"},
+					Status:         corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Fail (insufficient NGs & VPCs for requirements)",
+			rule: v1alpha1.ServiceQuotaRule{
+				Name:        "vpc",
+				Region:      "us-west-1",
+				ServiceCode: "vpc",
+				ServiceQuotas: []v1alpha1.ServiceQuota{
+					{
+						Name:     "NAT gateways per Availability Zone",
+						Required: 3,
+					},
+					{
+						Name:          "VPCs per Region",
+						BufferPercent: 90,
+					},
+				},
+			},
+			mockQuotas: []sqtypes.ServiceQuota{
+				{
+					QuotaName: util.Ptr("NAT gateways per Availability Zone"),
+					Value:     util.Ptr(3.0),
+				},
+				{
+					QuotaName: util.Ptr("VPCs per Region"),
+					Value:     util.Ptr(5.0),
+				},
+			},
+			expectedResult: types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "aws-service-quota",
+					ValidationRule: "validation-vpc",
+					Message:        "Usage for one or more service quotas exceeded the specified buffer",
+					Details: []string{
+						"NAT gateways per Availability Zone: quota: 3, required: 3, max. usage: 1, max. usage entity: us-west-1",
+						"VPCs per Region: quota: 5, buffer: 90%, max. usage: 1, max. usage entity: us-west-1",
+					},
+					Failures: []string{
+						"Remaining quota 2, less than required 3, for service vpc and quota NAT gateways per Availability Zone",
+						"Remaining quota 80.0%, less than buffer 90%, for service vpc and quota VPCs per Region",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
+		{
+			name: "Pass (sufficient NGs & VPCs for requirements)",
+			rule: v1alpha1.ServiceQuotaRule{
+				Name:        "vpc",
+				Region:      "us-west-1",
+				ServiceCode: "vpc",
+				ServiceQuotas: []v1alpha1.ServiceQuota{
+					{
+						Name:     "NAT gateways per Availability Zone",
+						Buffer:   1,
+						Required: 3,
+					},
+					{
+						Name:          "VPCs per Region",
+						BufferPercent: 50,
+					},
+				},
+			},
+			mockQuotas: []sqtypes.ServiceQuota{
+				{
+					QuotaName: util.Ptr("NAT gateways per Availability Zone"),
+					Value:     util.Ptr(5.0),
+				},
+				{
+					QuotaName: util.Ptr("VPCs per Region"),
+					Value:     util.Ptr(5.0),
+				},
+			},
+			expectedResult: types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "aws-service-quota",
+					ValidationRule: "validation-vpc",
+					Message:        "Usage for all service quotas is below specified buffer",
+					Details: []string{
+						"NAT gateways per Availability Zone: quota: 5, buffer: 1, required: 3, max. usage: 1, max. usage entity: us-west-1",
+						"VPCs per Region: quota: 5, buffer: 50%, max. usage: 1, max. usage entity: us-west-1",
+					},
+					Failures: nil,
+					Status:   corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Fail (quota without usage metric)",
+			rule: v1alpha1.ServiceQuotaRule{
+				Name:        "ec2-instances",
+				Region:      "us-west-1",
+				ServiceCode: "ec2",
+				ServiceQuotas: []v1alpha1.ServiceQuota{
+					{
+						Name:   "Dedicated Hosts per Region",
+						Buffer: 1,
+					},
+				},
+			},
+			mockQuotas: []sqtypes.ServiceQuota{
+				{
+					QuotaName: util.Ptr("Dedicated Hosts per Region"),
+					Value:     util.Ptr(5.0),
+				},
+			},
+			expectedResult: types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "aws-service-quota",
+					ValidationRule: "validation-ec2-instances",
+					Message:        "Usage for all service quotas is below specified buffer",
+					Details:        []string{},
+					Failures:       nil,
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+			expectedError: errors.New("service quota Dedicated Hosts per Region has no usage metric, so its usage can't be determined"),
+		},
 	}
 	for _, c := range cs {
 		fmt.Printf("Executing test: %s\n", c.name)
@@ -11,6 +11,7 @@ import (
 
 	"github.com/spectrocloud-labs/validator-plugin-aws/api/v1alpha1"
 	"github.com/spectrocloud-labs/validator-plugin-aws/internal/constants"
+	aws_utils "github.com/spectrocloud-labs/validator-plugin-aws/internal/utils/aws"
 	vapi "github.com/spectrocloud-labs/validator/api/v1alpha1"
 	vapiconstants "github.com/spectrocloud-labs/validator/pkg/constants"
 	vapitypes "github.com/spectrocloud-labs/validator/pkg/types"
@@ -66,7 +67,14 @@ func (s *TagRuleService) ReconcileTagRule(rule v1alpha1.TagRule) (*vapitypes.Val
 				foundArns[*s.SubnetArn] = true
 			}
 		}
+		partition := aws_utils.PartitionForRegion(rule.Region)
 		for _, arn := range rule.ARNs {
+			if a, err := aws_utils.ParseARN(arn); err == nil && a.Partition != partition {
+				failures = append(failures, fmt.Sprintf(
+					"Subnet with ARN %s is in partition %s, but region %s is in partition %s", arn, a.Partition, rule.Region, partition,
+				))
+				continue
+			}
 			_, ok := foundArns[arn]
 			if !ok {
 				failures = append(failures, fmt.Sprintf("Subnet with ARN %s missing tag %s=%s", arn, rule.Key, rule.ExpectedValue))
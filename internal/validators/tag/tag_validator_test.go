@@ -34,6 +34,13 @@ var tagService = NewTagRuleService(logr.Logger{}, tagApiMock{
 				},
 			},
 		},
+		"tag:kubernetes.io/role/internal-elb=1": {
+			Subnets: []ec2types.Subnet{
+				{
+					SubnetArn: util.Ptr("arn:aws-us-gov:ec2:us-gov-west-1:123456789012:subnet/subnet-0123456789abcdef0"),
+				},
+			},
+		},
 	},
 })
 
@@ -90,6 +97,52 @@ func TestTagValidation(t *testing.T) {
 				State: util.Ptr(vapi.ValidationSucceeded),
 			},
 		},
+		{
+			name: "Pass (GovCloud)",
+			rule: v1alpha1.TagRule{
+				Name:          "InternalELBSubnetTag",
+				Key:           "kubernetes.io/role/internal-elb",
+				ExpectedValue: "1",
+				Region:        "us-gov-west-1",
+				ResourceType:  "subnet",
+				ARNs:          []string{"arn:aws-us-gov:ec2:us-gov-west-1:123456789012:subnet/subnet-0123456789abcdef0"},
+			},
+			expectedResult: types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "aws-tag",
+					ValidationRule: "validation-InternalELBSubnetTag",
+					Message:        "All required subnet tags were found",
+					Details:        []string{},
+					Failures:       nil,
+					Status:         corev1.ConditionTrue,
+				},
+				State: util.Ptr(vapi.ValidationSucceeded),
+			},
+		},
+		{
+			name: "Fail (partition mismatch)",
+			rule: v1alpha1.TagRule{
+				Name:          "InternalELBSubnetTag",
+				Key:           "kubernetes.io/role/internal-elb",
+				ExpectedValue: "1",
+				Region:        "us-gov-west-1",
+				ResourceType:  "subnet",
+				ARNs:          []string{"arn:aws:ec2:us-west-1:123456789012:subnet/subnet-0123456789abcdef0"},
+			},
+			expectedResult: types.ValidationRuleResult{
+				Condition: &vapi.ValidationCondition{
+					ValidationType: "aws-tag",
+					ValidationRule: "validation-InternalELBSubnetTag",
+					Message:        "One or more required subnet tags was not found",
+					Details:        []string{},
+					Failures: []string{
+						"Subnet with ARN arn:aws:ec2:us-west-1:123456789012:subnet/subnet-0123456789abcdef0 is in partition aws, but region us-gov-west-1 is in partition aws-us-gov",
+					},
+					Status: corev1.ConditionFalse,
+				},
+				State: util.Ptr(vapi.ValidationFailed),
+			},
+		},
 	}
 	for _, c := range cs {
 		result, err := tagService.ReconcileTagRule(c.rule)
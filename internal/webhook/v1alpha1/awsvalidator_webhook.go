@@ -0,0 +1,98 @@
+// Package v1alpha1 contains the admission webhooks for v1alpha1 AwsValidators.
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/spectrocloud-labs/validator-plugin-aws/api/v1alpha1"
+	"github.com/spectrocloud-labs/validator-plugin-aws/internal/catalog"
+)
+
+// SetupAwsValidatorWebhookWithManager registers the AwsValidator validating webhook with the manager
+func SetupAwsValidatorWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&v1alpha1.AwsValidator{}).
+		WithValidator(&AwsValidatorCustomValidator{}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-validation-spectrocloud-labs-v1alpha1-awsvalidator,mutating=false,failurePolicy=fail,sideEffects=None,groups=validation.spectrocloud.labs,resources=awsvalidators,verbs=create;update,versions=v1alpha1,name=vawsvalidator.kb.io,admissionReviewVersions=v1
+
+// AwsValidatorCustomValidator rejects AwsValidators whose IAM policy documents contain malformed or unknown IAM actions
+// or resource types, per the IAM action catalog
+type AwsValidatorCustomValidator struct{}
+
+var _ admission.CustomValidator = &AwsValidatorCustomValidator{}
+
+// ValidateCreate validates an AwsValidator on creation
+func (v *AwsValidatorCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return v.validate(obj)
+}
+
+// ValidateUpdate validates an AwsValidator on update
+func (v *AwsValidatorCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	return v.validate(newObj)
+}
+
+// ValidateDelete allows all AwsValidator deletions
+func (v *AwsValidatorCustomValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *AwsValidatorCustomValidator) validate(obj runtime.Object) (admission.Warnings, error) {
+	validator, ok := obj.(*v1alpha1.AwsValidator)
+	if !ok {
+		return nil, fmt.Errorf("expected an AwsValidator but got %T", obj)
+	}
+	if errs := validateSpec(validator.Spec); len(errs) > 0 {
+		return nil, apierrors.NewInvalid(v1alpha1.GroupVersion.WithKind("AwsValidator").GroupKind(), validator.Name, errs)
+	}
+	return nil, nil
+}
+
+type policyRule interface {
+	IAMPolicies() []v1alpha1.PolicyDocument
+}
+
+// validateSpec validates the actions and resources in all of an AwsValidator's IAM policy documents against the IAM action catalog
+func validateSpec(spec v1alpha1.AwsValidatorSpec) field.ErrorList {
+	path := field.NewPath("spec")
+	errs := field.ErrorList{}
+	errs = append(errs, validateRules(path.Child("iamRoleRules"), spec.IamRoleRules)...)
+	errs = append(errs, validateRules(path.Child("iamUserRules"), spec.IamUserRules)...)
+	errs = append(errs, validateRules(path.Child("iamGroupRules"), spec.IamGroupRules)...)
+	errs = append(errs, validateRules(path.Child("iamPolicyRules"), spec.IamPolicyRules)...)
+	errs = append(errs, validateRules(path.Child("iamIrsaRules"), spec.IamIrsaRules)...)
+	errs = append(errs, validateRules(path.Child("iamCustomPolicyRules"), spec.IamCustomPolicyRules)...)
+	return errs
+}
+
+func validateRules[R policyRule](path *field.Path, rules []R) field.ErrorList {
+	c := catalog.Default()
+	errs := field.ErrorList{}
+	for i, rule := range rules {
+		for j, p := range rule.IAMPolicies() {
+			for k, s := range p.Statements {
+				sPath := path.Index(i).Child("iamPolicies").Index(j).Child("statements").Index(k)
+				for l, a := range s.Actions {
+					if msg := c.LintAction(a); msg != "" {
+						errs = append(errs, field.Invalid(sPath.Child("actions").Index(l), a, fmt.Sprintf("%s (IAM action catalog %s)", msg, c.Version)))
+					}
+				}
+				for l, r := range s.Resources {
+					if msg := c.LintResource(r); msg != "" {
+						errs = append(errs, field.Invalid(sPath.Child("resources").Index(l), r, fmt.Sprintf("%s (IAM action catalog %s)", msg, c.Version)))
+					}
+				}
+			}
+		}
+	}
+	return errs
+}
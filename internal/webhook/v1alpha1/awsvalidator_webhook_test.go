@@ -0,0 +1,74 @@
+package v1alpha1
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/spectrocloud-labs/validator-plugin-aws/api/v1alpha1"
+)
+
+func awsValidator(actions, resources []string) *v1alpha1.AwsValidator {
+	return &v1alpha1.AwsValidator{
+		ObjectMeta: metav1.ObjectMeta{Name: "awsvalidator"},
+		Spec: v1alpha1.AwsValidatorSpec{
+			IamRoleRules: []v1alpha1.IamRoleRule{
+				{
+					IamRoleName: "role",
+					Policies: []v1alpha1.PolicyDocument{
+						{
+							Name:    "policy",
+							Version: "1",
+							Statements: []v1alpha1.StatementEntry{
+								{Effect: "Allow", Actions: actions, Resources: resources},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestValidateCreate(t *testing.T) {
+	cs := []struct {
+		name      string
+		validator *v1alpha1.AwsValidator
+		expected  []string
+	}{
+		{
+			name:      "Pass",
+			validator: awsValidator([]string{"ec2:DescribeSubnets", "ec2:Describe*", "eks:DescribeCluster", "*"}, []string{"*", "arn:aws:ec2:*:*:subnet/*"}),
+		},
+		{
+			name:      "Fail (unknown action & resource type)",
+			validator: awsValidator([]string{"ec2:DescribeSubnet", "ec2"}, []string{"arn:aws:iam::123456789012:roles/foo"}),
+			expected: []string{
+				`spec.iamRoleRules[0].iamPolicies[0].statements[0].actions[0]: Invalid value: "ec2:DescribeSubnet": unknown action ec2:DescribeSubnet (IAM action catalog 2024-03-01)`,
+				`spec.iamRoleRules[0].iamPolicies[0].statements[0].actions[1]: Invalid value: "ec2": malformed action ec2 (IAM action catalog 2024-03-01)`,
+				`spec.iamRoleRules[0].iamPolicies[0].statements[0].resources[0]: Invalid value: "arn:aws:iam::123456789012:roles/foo": unknown iam resource type roles in resource arn:aws:iam::123456789012:roles/foo (IAM action catalog 2024-03-01)`,
+			},
+		},
+	}
+	v := &AwsValidatorCustomValidator{}
+	for _, c := range cs {
+		_, err := v.ValidateCreate(context.Background(), c.validator)
+		if len(c.expected) == 0 {
+			if err != nil {
+				t.Errorf("%s: expected no error, got %v", c.name, err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("%s: expected an error, got nil", c.name)
+			continue
+		}
+		for _, e := range c.expected {
+			if !strings.Contains(err.Error(), e) {
+				t.Errorf("%s: expected error to contain %q, got %v", c.name, e, err)
+			}
+		}
+	}
+}